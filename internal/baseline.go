@@ -0,0 +1,247 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// testChange is an enumeration of the changes in the result of a test,
+// compared with a baseline.
+//
+//	tcNewFailure     // the test failed but did not fail in the baseline (or is not in the baseline)
+//	tcStillFailing   // the test failed and also failed in the baseline
+//	tcFixed          // the test passed but failed in the baseline
+//	tcAdded          // the test did not fail and is not in the baseline
+//	tcRemoved        // the test is in the baseline but not in the testrun
+//
+// The zero value is tcNewFailure.
+type testChange int
+
+const (
+	tcNewFailure   testChange = iota // the test failed but did not fail in the baseline
+	tcStillFailing                   // the test failed and also failed in the baseline
+	tcFixed                          // the test passed but failed in the baseline
+	tcAdded                          // the test did not fail and is not in the baseline
+	tcRemoved                        // the test is in the baseline but not in the testrun
+)
+
+// testChanges is the list of changes, in the order they are reported.
+var testChanges = []testChange{tcNewFailure, tcStillFailing, tcFixed, tcAdded, tcRemoved}
+
+// String returns a description of the change.
+func (c testChange) String() string {
+	return map[testChange]string{
+		tcNewFailure:   "new failure",
+		tcStillFailing: "still failing",
+		tcFixed:        "fixed",
+		tcAdded:        "added",
+		tcRemoved:      "removed",
+	}[c]
+}
+
+// changedTest identifies a test with a changed result.
+type changedTest struct {
+	packageName string // the package containing the test
+	path        string // the path to (name of) the test
+}
+
+// changes contains the tests in a testrun with results that changed,
+// compared with a baseline, keyed by change.  Tests in each change are in
+// the order of the testrun (or, for removed tests, the baseline).
+type changes map[testChange][]changedTest
+
+// loadBaseline reads a baseline testrun from the specified file, either a
+// go test -json log or a json report (identified by a schema version).
+func loadBaseline(filename string) (*testrun, error) {
+	b, err := osReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := jsonTestrun{}
+	if json.Unmarshal(b, &doc) != nil || doc.Schema == 0 {
+		tr := &testrun{}
+		if err := (&parser{}).parse(bytes.NewReader(b), tr); err != nil {
+			return nil, err
+		}
+		return tr, nil
+	}
+	if doc.Schema > jsonSchemaVersion {
+		return nil, fmt.Errorf("%w: %s: unsupported schema version: %d", ErrInvalidBaseline, filename, doc.Schema)
+	}
+	return doc.testrun(), nil
+}
+
+// testrun returns a testrun from a json report.  Only the information
+// required of a baseline is restored (the packages and the results and
+// elapsed times of the tests and packages).
+func (doc jsonTestrun) testrun() *testrun {
+	seconds := func(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+	results := map[string]testResult{
+		trFailed.String():  trFailed,
+		trPassed.String():  trPassed,
+		trSkipped.String(): trSkipped,
+	}
+
+	tr := &testrun{elapsed: seconds(doc.Elapsed)}
+	for _, jp := range doc.Packages {
+		p := &packageinfo{
+			name:        jp.Name,
+			passed:      jp.Passed,
+			elapsed:     seconds(jp.Elapsed),
+			buildFailed: jp.BuildFailed,
+			failed:      jp.Failed,
+			coverage:    jp.Coverage,
+		}
+		for _, jt := range jp.Tests {
+			p.tests = append(p.tests, &testinfo{
+				path:        jt.Name,
+				packageName: jp.Name,
+				result:      results[jt.Result],
+				elapsed:     seconds(jt.Elapsed),
+			})
+		}
+		tr.packages = append(tr.packages, p)
+	}
+	tr.recount()
+	return tr
+}
+
+// compare returns the changes in the results of the tests in a testrun,
+// compared with a baseline.  A test is identified by its package and path.
+//
+// A test that failed is a new failure, unless it also failed in the
+// baseline; a test that passed having failed in the baseline is fixed.
+// Tests in packages that failed to build are not reported as removed (the
+// build failure is reported).
+func compare(baseline, tr *testrun) changes {
+	type key struct{ pkg, path string }
+
+	base := map[key]*testinfo{}
+	for _, p := range baseline.packages {
+		for _, t := range p.tests {
+			base[key{p.name, t.path}] = t
+		}
+	}
+
+	result := changes{}
+	add := func(c testChange, pkg, path string) {
+		result[c] = append(result[c], changedTest{packageName: pkg, path: path})
+	}
+
+	found := map[key]bool{}
+	for _, p := range tr.packages {
+		for _, t := range p.tests {
+			k := key{p.name, t.path}
+			found[k] = true
+			bt, ok := base[k]
+			switch {
+			case t.result == trFailed && ok && bt.result == trFailed:
+				add(tcStillFailing, p.name, t.path)
+			case t.result == trFailed:
+				add(tcNewFailure, p.name, t.path)
+			case !ok:
+				add(tcAdded, p.name, t.path)
+			case t.result == trPassed && bt.result == trFailed:
+				add(tcFixed, p.name, t.path)
+			}
+		}
+	}
+
+	buildFailed := map[string]bool{}
+	for _, p := range tr.packages {
+		buildFailed[p.name] = p.buildFailed
+	}
+	for _, p := range baseline.packages {
+		if buildFailed[p.name] {
+			continue
+		}
+		for _, t := range p.tests {
+			if !found[key{p.name, t.path}] {
+				add(tcRemoved, p.name, t.path)
+			}
+		}
+	}
+	return result
+}
+
+// count returns the total number of changed tests.
+func (c changes) count() int {
+	n := 0
+	for _, tc := range testChanges {
+		n += len(c[tc])
+	}
+	return n
+}
+
+// regressionThreshold determines the increase in the elapsed time of a test
+// (or package), compared with a baseline, that is a duration regression.
+type regressionThreshold struct {
+	percent int           // the minimum increase, as a %age of the elapsed time in the baseline
+	min     time.Duration // the minimum increase
+}
+
+// defaultRegressionThreshold is the regression threshold used if not
+// specified.
+var defaultRegressionThreshold = regressionThreshold{percent: 50, min: time.Second}
+
+// durationRegression identifies a test (or package) that took longer than
+// in a baseline.
+type durationRegression struct {
+	packageName string        // the package (containing the test)
+	path        string        // the path to (name of) the test ("": the package)
+	baseline    time.Duration // the elapsed time in the baseline
+	elapsed     time.Duration // the elapsed time in the testrun
+}
+
+// durationRegressions returns the tests and packages in a testrun that took
+// longer than in a baseline by more than both the minimum increase and the
+// minimum %age increase of a threshold (any increase of more than the
+// minimum is a regression if the elapsed time in the baseline is zero).
+//
+// Regressions are returned in the order of the testrun, with a package
+// preceding the tests in the package.  Skipped tests and packages that
+// failed to build (in either testrun) are not compared.
+func durationRegressions(baseline, tr *testrun, th regressionThreshold) []durationRegression {
+	type key struct{ pkg, path string }
+
+	base := map[key]time.Duration{}
+	for _, p := range baseline.packages {
+		if p.buildFailed {
+			continue
+		}
+		base[key{p.name, ""}] = p.elapsed
+		for _, t := range p.tests {
+			if t.result != trSkipped {
+				base[key{p.name, t.path}] = t.elapsed
+			}
+		}
+	}
+
+	result := []durationRegression{}
+	check := func(pkg, path string, elapsed time.Duration) {
+		b, ok := base[key{pkg, path}]
+		if !ok {
+			return
+		}
+		delta := elapsed - b
+		if delta > th.min && (b == 0 || delta*100 > b*time.Duration(th.percent)) {
+			result = append(result, durationRegression{packageName: pkg, path: path, baseline: b, elapsed: elapsed})
+		}
+	}
+
+	for _, p := range tr.packages {
+		if p.buildFailed {
+			continue
+		}
+		check(p.name, "", p.elapsed)
+		for _, t := range p.tests {
+			if t.result != trSkipped {
+				check(p.name, t.path, t.elapsed)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestBaseline(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "load/file not found",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) { return nil, fs.ErrNotExist })()
+
+				// ACT
+				_, err := loadBaseline("baseline.json")
+
+				// ASSERT
+				test.Error(t, err).Is(fs.ErrNotExist)
+			},
+		},
+		{scenario: "load/go test log",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte(`{"Action":"start","Package":"pkg"}
+{"Action":"run","Package":"pkg","Test":"Test1"}
+{"Action":"fail","Package":"pkg","Test":"Test1","Elapsed":0.5}
+{"Action":"fail","Package":"pkg","Elapsed":1}
+`), nil
+				})()
+
+				// ACT
+				result, err := loadBaseline("baseline.json")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result.packages)).Equals(1)
+				test.That(t, result.packages[0].name).Equals("pkg")
+				test.That(t, len(result.packages[0].tests)).Equals(1)
+				test.That(t, result.packages[0].tests[0].path).Equals("Test1")
+				test.That(t, result.packages[0].tests[0].result).Equals(trFailed)
+			},
+		},
+		{scenario: "load/json report",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte(`{"schema": 1, "elapsed": 2, "packages": [
+						{"name": "pkg", "passed": true, "elapsed": 1.5, "tests": [
+							{"name": "Test1", "result": "passed", "elapsed": 0.5},
+							{"name": "Test2", "result": "skipped", "elapsed": 0}
+						]}
+					]}`), nil
+				})()
+
+				// ACT
+				result, err := loadBaseline("baseline.json")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.elapsed).Equals(2 * time.Second)
+				test.That(t, result.numPassed).Equals(1)
+				test.That(t, result.numSkipped).Equals(1)
+				test.That(t, result.packages).Equals([]*packageinfo{{
+					name:    "pkg",
+					passed:  true,
+					elapsed: 1500 * time.Millisecond,
+					tests: []*testinfo{
+						{path: "Test1", packageName: "pkg", result: trPassed, elapsed: 500 * time.Millisecond},
+						{path: "Test2", packageName: "pkg", result: trSkipped},
+					},
+				}})
+			},
+		},
+		{scenario: "load/json report/unsupported schema",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte(`{"schema": 99, "packages": []}`), nil
+				})()
+
+				// ACT
+				_, err := loadBaseline("baseline.json")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidBaseline)
+			},
+		},
+		{scenario: "compare",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				baseline := &testrun{packages: []*packageinfo{
+					{name: "pkg", tests: []*testinfo{
+						{path: "TestNewFailure", result: trPassed},
+						{path: "TestStillFailing", result: trFailed},
+						{path: "TestFixed", result: trFailed},
+						{path: "TestSkipped", result: trFailed},
+						{path: "TestUnchanged", result: trPassed},
+						{path: "TestRemoved", result: trPassed},
+					}},
+					{name: "build", tests: []*testinfo{
+						{path: "TestBuild", result: trPassed},
+					}},
+				}}
+				tr := &testrun{packages: []*packageinfo{
+					{name: "pkg", tests: []*testinfo{
+						{path: "TestNewFailure", result: trFailed},
+						{path: "TestStillFailing", result: trFailed},
+						{path: "TestFixed", result: trPassed},
+						{path: "TestSkipped", result: trSkipped},
+						{path: "TestUnchanged", result: trPassed},
+						{path: "TestAdded", result: trPassed},
+						{path: "TestAddedFailed", result: trFailed},
+					}},
+					{name: "build", buildFailed: true},
+				}}
+
+				// ACT
+				result := compare(baseline, tr)
+
+				// ASSERT
+				test.That(t, result).Equals(changes{
+					tcNewFailure: {
+						{packageName: "pkg", path: "TestNewFailure"},
+						{packageName: "pkg", path: "TestAddedFailed"},
+					},
+					tcStillFailing: {{packageName: "pkg", path: "TestStillFailing"}},
+					tcFixed:        {{packageName: "pkg", path: "TestFixed"}},
+					tcAdded:        {{packageName: "pkg", path: "TestAdded"}},
+					tcRemoved:      {{packageName: "pkg", path: "TestRemoved"}},
+				})
+				test.That(t, result.count()).Equals(6)
+			},
+		},
+		{scenario: "compare/no changes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tr := &testrun{packages: []*packageinfo{
+					{name: "pkg", tests: []*testinfo{{path: "Test1", result: trPassed}}},
+				}}
+
+				// ACT
+				result := compare(tr, tr)
+
+				// ASSERT
+				test.That(t, result).Equals(changes{})
+				test.That(t, result.count()).Equals(0)
+			},
+		},
+		{scenario: "regressions",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				baseline := &testrun{packages: []*packageinfo{
+					{name: "pkg", elapsed: 10 * time.Second, tests: []*testinfo{
+						{path: "TestSlower", result: trPassed, elapsed: 2 * time.Second},
+						{path: "TestBelowPercent", result: trPassed, elapsed: 4 * time.Second},
+						{path: "TestBelowMin", result: trPassed, elapsed: 100 * time.Millisecond},
+						{path: "TestZero", result: trPassed},
+						{path: "TestSkipped", result: trPassed, elapsed: 1 * time.Second},
+						{path: "TestFaster", result: trPassed, elapsed: 3 * time.Second},
+					}},
+					{name: "fast", elapsed: 1 * time.Second},
+					{name: "build", elapsed: 1 * time.Second},
+				}}
+				tr := &testrun{packages: []*packageinfo{
+					{name: "pkg", elapsed: 20 * time.Second, tests: []*testinfo{
+						{path: "TestSlower", result: trFailed, elapsed: 5 * time.Second},
+						{path: "TestBelowPercent", result: trPassed, elapsed: 5500 * time.Millisecond},
+						{path: "TestBelowMin", result: trPassed, elapsed: 900 * time.Millisecond},
+						{path: "TestZero", result: trPassed, elapsed: 1500 * time.Millisecond},
+						{path: "TestSkipped", result: trSkipped, elapsed: 5 * time.Second},
+						{path: "TestFaster", result: trPassed, elapsed: 1 * time.Second},
+						{path: "TestAdded", result: trPassed, elapsed: 5 * time.Second},
+					}},
+					{name: "fast", elapsed: 1 * time.Second},
+					{name: "build", buildFailed: true},
+				}}
+
+				// ACT
+				result := durationRegressions(baseline, tr, defaultRegressionThreshold)
+
+				// ASSERT
+				test.That(t, result).Equals([]durationRegression{
+					{packageName: "pkg", baseline: 10 * time.Second, elapsed: 20 * time.Second},
+					{packageName: "pkg", path: "TestSlower", baseline: 2 * time.Second, elapsed: 5 * time.Second},
+					{packageName: "pkg", path: "TestZero", elapsed: 1500 * time.Millisecond},
+				})
+			},
+		},
+		{scenario: "regressions/threshold",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				baseline := &testrun{packages: []*packageinfo{
+					{name: "pkg", tests: []*testinfo{
+						{path: "Test1", result: trPassed, elapsed: 100 * time.Millisecond},
+						{path: "Test2", result: trPassed, elapsed: 100 * time.Millisecond},
+					}},
+				}}
+				tr := &testrun{packages: []*packageinfo{
+					{name: "pkg", tests: []*testinfo{
+						{path: "Test1", result: trPassed, elapsed: 150 * time.Millisecond},
+						{path: "Test2", result: trPassed, elapsed: 105 * time.Millisecond},
+					}},
+				}}
+
+				// ACT
+				result := durationRegressions(baseline, tr, regressionThreshold{percent: 10, min: 10 * time.Millisecond})
+
+				// ASSERT
+				test.That(t, result).Equals([]durationRegression{
+					{packageName: "pkg", path: "Test1", baseline: 100 * time.Millisecond, elapsed: 150 * time.Millisecond},
+				})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
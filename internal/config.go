@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// defaultConfigFilename is the name of the configuration file read if no
+// configuration file is specified.  It is not an error if this file does
+// not exist.
+const defaultConfigFilename = ".test-report.json"
+
+// osReadFile is a function variable to facilitate testing.
+var osReadFile = os.ReadFile
+
+// config is the content of a configuration file.  Any value in the
+// configuration replaces the default for the corresponding option; values
+// specified on the command line take precedence over the configuration.
+type config struct {
+	SourceURL    string `json:"sourceUrl"`
+	ModuleRoot   string `json:"moduleRoot"`
+	SnippetLines int    `json:"snippetLines"`
+	Collapse     bool   `json:"collapse"`
+	MaxLines     int    `json:"maxLines"`
+	MaxSize      int    `json:"maxSize"`
+	History      string `json:"history"`
+	HistoryRuns  int    `json:"historyRuns"`
+	Slowest      int    `json:"slowest"`
+	Budget       string `json:"durationBudget"`
+	Regression   struct {
+		Threshold *int   `json:"threshold"`
+		Min       string `json:"min"`
+	} `json:"regression"`
+	Thresholds struct {
+		Orange *int `json:"orange"`
+		Yellow *int `json:"yellow"`
+	} `json:"thresholds"`
+}
+
+// loadConfig reads the configuration from the specified file.  If the file
+// does not exist and is not required an empty configuration is returned.
+func loadConfig(filename string, required bool) (*config, error) {
+	cfg := &config{}
+
+	b, err := osReadFile(filename)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !required:
+		return cfg, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, filename, err)
+	}
+	return cfg, nil
+}
+
+// applyThresholds applies any thresholds in the configuration to t.
+func (cfg *config) applyThresholds(t *thresholds) {
+	if cfg.Thresholds.Orange != nil {
+		t.orange = *cfg.Thresholds.Orange
+	}
+	if cfg.Thresholds.Yellow != nil {
+		t.yellow = *cfg.Thresholds.Yellow
+	}
+}
+
+// durationBudget returns the duration budget in the configuration (0: no
+// budget).
+func (cfg *config) durationBudget() (time.Duration, error) {
+	if cfg.Budget == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(cfg.Budget)
+	if err != nil {
+		return 0, fmt.Errorf("%w: durationBudget: %w", ErrInvalidConfig, err)
+	}
+	return d, nil
+}
+
+// regressionThreshold returns the regression threshold in the configuration,
+// with defaultRegressionThreshold for any value not specified.
+func (cfg *config) regressionThreshold() (regressionThreshold, error) {
+	th := defaultRegressionThreshold
+	if cfg.Regression.Threshold != nil {
+		th.percent = *cfg.Regression.Threshold
+	}
+	if cfg.Regression.Min != "" {
+		d, err := time.ParseDuration(cfg.Regression.Min)
+		if err != nil {
+			return th, fmt.Errorf("%w: regression.min: %w", ErrInvalidConfig, err)
+		}
+		th.min = d
+	}
+	return th, nil
+}
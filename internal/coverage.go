@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// coverProfile is the statement coverage of each package in a coverage
+// profile (as written by go test -coverprofile), keyed by import path.
+type coverProfile map[string]*coverCount
+
+// coverCount is a count of the statements (and statements covered) in a
+// package or testrun.
+type coverCount struct {
+	statements int
+	covered    int
+}
+
+// percent returns the %age of statements covered.  If there are no
+// statements, nil is returned.
+func (c coverCount) percent() *float64 {
+	if c.statements == 0 {
+		return nil
+	}
+	pct := float64(c.covered*100) / float64(c.statements)
+	return &pct
+}
+
+// loadCoverProfile reads a coverage profile.  Each line of the profile
+// (other than "mode:" lines) identifies a block of statements in a file
+// and the number of times the block was executed:
+//
+//	<import path>/<file>:<line>.<col>,<line>.<col> <statements> <count>
+//
+// A block appearing more than once (e.g. in profiles combined from a
+// number of test runs) is counted once, as covered if it was executed in
+// any of them.
+func loadCoverProfile(filename string) (coverProfile, error) {
+	b, err := osReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := map[string]bool{}
+	stmts := map[string]int{}
+	for n, s := range strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n") {
+		if s == "" || strings.HasPrefix(s, "mode:") {
+			continue
+		}
+		f := strings.Fields(s)
+		if len(f) < 3 {
+			return nil, fmt.Errorf("%w: %s:%d", ErrInvalidCoverProfile, filename, n+1)
+		}
+		block := strings.Join(f[:len(f)-2], " ")
+		ns, err1 := strconv.Atoi(f[len(f)-2])
+		count, err2 := strconv.Atoi(f[len(f)-1])
+		if err1 != nil || err2 != nil || !strings.Contains(block, ":") {
+			return nil, fmt.Errorf("%w: %s:%d", ErrInvalidCoverProfile, filename, n+1)
+		}
+		blocks[block] = blocks[block] || count > 0
+		stmts[block] = ns
+	}
+
+	profile := coverProfile{}
+	for block, covered := range blocks {
+		file := block[:strings.LastIndex(block, ":")]
+		pkg := path.Dir(file)
+		if profile[pkg] == nil {
+			profile[pkg] = &coverCount{}
+		}
+		profile[pkg].statements += stmts[block]
+		if covered {
+			profile[pkg].covered += stmts[block]
+		}
+	}
+	return profile, nil
+}
+
+// apply sets the coverage of each package in a testrun from the profile
+// and the coverage of the testrun to the total of all packages in the
+// profile, weighted by the number of statements in each package.
+func (cp coverProfile) apply(tr *testrun) {
+	total := coverCount{}
+	for _, c := range cp {
+		total.statements += c.statements
+		total.covered += c.covered
+	}
+	for _, p := range tr.packages {
+		if c, ok := cp[p.name]; ok {
+			p.coverage = c.percent()
+		}
+	}
+	tr.coverage = total.percent()
+}
+
+// meanCoverage returns the mean of the coverage reported for each package
+// in a testrun.  If no package reported coverage, nil is returned.
+//
+// Without a coverage profile the number of statements in each package is
+// not known, so each package is weighted equally.
+func (tr *testrun) meanCoverage() *float64 {
+	n, total := 0, 0.0
+	for _, p := range tr.packages {
+		if p.coverage != nil {
+			n++
+			total += *p.coverage
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	mean := total / float64(n)
+	return &mean
+}
@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestCoverage(t *testing.T) {
+	// ARRANGE
+	pct := func(f float64) *float64 { return &f }
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "load/file not found",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) { return nil, fs.ErrNotExist })()
+
+				// ACT
+				_, err := loadCoverProfile("cover.out")
+
+				// ASSERT
+				test.Error(t, err).Is(fs.ErrNotExist)
+			},
+		},
+		{scenario: "load/invalid",
+			exec: func(t *testing.T) {
+				for _, s := range []string{
+					"mode: set\ngithub.com/foo/pkg/foo.go:1.1,2.2 1\n",
+					"mode: set\ngithub.com/foo/pkg/foo.go:1.1,2.2 one 1\n",
+					"mode: set\nfoo 1 1\n",
+				} {
+					// ARRANGE
+					defer test.Using(&osReadFile, func(string) ([]byte, error) { return []byte(s), nil })()
+
+					// ACT
+					_, err := loadCoverProfile("cover.out")
+
+					// ASSERT
+					test.Error(t, err).Is(ErrInvalidCoverProfile)
+				}
+			},
+		},
+		{scenario: "load/valid",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte("mode: set\n" +
+						"github.com/foo/pkg/foo.go:3.24,5.2 2 1\n" +
+						"github.com/foo/pkg/foo.go:7.24,9.2 1 0\n" +
+						"github.com/foo/pkg/bar.go:3.24,5.2 1 0\n" +
+						"mode: set\n" +
+						"github.com/foo/pkg/bar.go:3.24,5.2 1 1\n" +
+						"github.com/foo/other/other.go:3.24,5.2 4 0\n"), nil
+				})()
+
+				// ACT
+				result, err := loadCoverProfile("cover.out")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals(coverProfile{
+					"github.com/foo/pkg":   {statements: 4, covered: 3},
+					"github.com/foo/other": {statements: 4, covered: 0},
+				})
+			},
+		},
+		{scenario: "apply",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				cp := coverProfile{
+					"github.com/foo/pkg":   {statements: 4, covered: 3},
+					"github.com/foo/other": {statements: 4, covered: 0},
+				}
+				tr := &testrun{packages: []*packageinfo{
+					{name: "github.com/foo/pkg", coverage: pct(50)},
+					{name: "github.com/foo/untested"},
+				}}
+
+				// ACT
+				cp.apply(tr)
+
+				// ASSERT
+				test.That(t, tr.packages[0].coverage).Equals(pct(75))
+				test.That(t, tr.packages[1].coverage).IsNil()
+				test.That(t, tr.coverage).Equals(pct(37.5))
+			},
+		},
+		{scenario: "apply/no statements",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tr := &testrun{}
+
+				// ACT
+				coverProfile{}.apply(tr)
+
+				// ASSERT
+				test.That(t, tr.coverage).IsNil()
+			},
+		},
+		{scenario: "mean",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tr := &testrun{packages: []*packageinfo{
+					{coverage: pct(50)},
+					{},
+					{coverage: pct(100)},
+				}}
+
+				// ACT
+				result := tr.meanCoverage()
+
+				// ASSERT
+				test.That(t, result).Equals(pct(75))
+			},
+		},
+		{scenario: "mean/not reported",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tr := &testrun{packages: []*packageinfo{{}}}
+
+				// ACT
+				result := tr.meanCoverage()
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -3,5 +3,19 @@ package internal
 import "errors"
 
 var (
-	ErrNotPiped = errors.New("no piped input")
+	ErrInvalidBaseline     = errors.New("invalid baseline")
+	ErrInvalidConfig       = errors.New("invalid configuration file")
+	ErrInvalidCoverProfile = errors.New("invalid coverage profile")
+	ErrInvalidExitPolicy   = errors.New("invalid exit policy")
+	ErrInvalidFormat       = errors.New("invalid report format")
+	ErrInvalidHistory      = errors.New("invalid history file")
+	ErrInvalidInput        = errors.New("invalid input")
+	ErrInvalidOption       = errors.New("invalid option")
+	ErrInvalidThresholds   = errors.New("invalid thresholds")
+	ErrNoInputFiles        = errors.New("no input files match pattern")
+	ErrNoInputs            = errors.New("no inputs specified")
+	ErrNoModulePath        = errors.New("no module path in go.mod")
+	ErrNoStepSummary       = errors.New("GITHUB_STEP_SUMMARY is not set")
+	ErrNotPiped            = errors.New("no piped input")
+	ErrSizeLimitExceeded   = errors.New("size limit exceeded")
 )
@@ -0,0 +1,82 @@
+package internal
+
+import "fmt"
+
+// exit codes returned by a command generating a report.  A report is always
+// written unless an error occurs; the exit code identifies the first cause
+// (in the order listed) for which the exit policy fails the test run.
+const (
+	exitOK          = 0  // the test run satisfied the exit policy
+	exitFailed      = -1 // more tests failed than the maximum allowed
+	exitError       = -2 // an error occurred (e.g. no input or invalid options)
+	exitPassRate    = -3 // the pass rate was below the minimum required
+	exitSkipped     = -4 // tests were skipped (fail-on-skip)
+	exitNoTests     = -5 // the test run contained no tests (fail-on-no-tests)
+	exitBuildFailed = -6 // a package failed to build (fail-on-build-failure)
+	exitRegression  = -7 // tests or packages took longer than in the baseline (fail-on-regression)
+	exitFlaky       = -8 // tests both passed and failed when run more than once (fail-on-flaky)
+)
+
+// ExitError is the exit code of the executable if the options are invalid
+// (exitError).
+const ExitError = exitError
+
+// exitPolicy determines the exit code of a command generating a report.
+//
+// The zero value fails a test run if any test failed and is otherwise
+// satisfied.
+type exitPolicy struct {
+	minPassRate        int  // the minimum pass rate %age (0: no minimum)
+	maxFailed          int  // the maximum number of failed tests (-1: no maximum)
+	failOnSkip         bool // fail if any tests were skipped
+	failOnNoTests      bool // fail if there were no tests
+	failOnBuildFailure bool // fail if any package failed to build
+	failOnRegression   bool // fail if any test or package took longer than in the baseline (see durationRegressions)
+	failOnFlaky        bool // fail if any tests were flaky (a flaky test is otherwise passed if its final attempt passed)
+}
+
+// validate returns an error if the minimum pass rate is not a %age or the
+// maximum number of failed tests is less than -1.
+func (p exitPolicy) validate() error {
+	if p.minPassRate < 0 || p.minPassRate > 100 {
+		return fmt.Errorf("%w: minimum pass rate %d%% (must be 0-100)", ErrInvalidExitPolicy, p.minPassRate)
+	}
+	if p.maxFailed < -1 {
+		return fmt.Errorf("%w: maximum failed %d (must be -1 or more)", ErrInvalidExitPolicy, p.maxFailed)
+	}
+	return nil
+}
+
+// exitCode returns the exit code for a testrun, with any duration regressions
+// identified by comparison with a baseline.  Causes are tested in the
+// following order, with the exit code for the first cause returned:
+//
+//	build failure    // exitBuildFailed (if failOnBuildFailure)
+//	no tests         // exitNoTests (if failOnNoTests)
+//	failed tests     // exitFailed (if more than maxFailed, counting package and build failures)
+//	pass rate        // exitPassRate (if less than minPassRate)
+//	skipped tests    // exitSkipped (if failOnSkip)
+//	flaky tests      // exitFlaky (if failOnFlaky)
+//	regressions      // exitRegression (if failOnRegression)
+//
+// If none apply, exitOK is returned.
+func (p exitPolicy) exitCode(tr *testrun, regressions []durationRegression) int {
+	switch {
+	case p.failOnBuildFailure && tr.numBuildFailed > 0:
+		return exitBuildFailed
+	case p.failOnNoTests && tr.numTests == 0:
+		return exitNoTests
+	case p.maxFailed >= 0 && tr.numFailed+tr.numPackageFailed+tr.numBuildFailed > p.maxFailed:
+		return exitFailed
+	case tr.numTests > 0 && tr.percentPassed < p.minPassRate:
+		return exitPassRate
+	case p.failOnSkip && tr.numSkipped > 0:
+		return exitSkipped
+	case p.failOnFlaky && tr.numFlaky > 0:
+		return exitFlaky
+	case p.failOnRegression && len(regressions) > 0:
+		return exitRegression
+	default:
+		return exitOK
+	}
+}
@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestExitPolicy(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "validate/valid",
+			exec: func(t *testing.T) {
+				for _, p := range []exitPolicy{
+					{},
+					{minPassRate: 100, maxFailed: -1},
+					{maxFailed: 10},
+				} {
+					test.Error(t, p.validate()).IsNil()
+				}
+			},
+		},
+		{scenario: "validate/invalid",
+			exec: func(t *testing.T) {
+				for _, p := range []exitPolicy{
+					{minPassRate: -1},
+					{minPassRate: 101},
+					{maxFailed: -2},
+				} {
+					test.Error(t, p.validate()).Is(ErrInvalidExitPolicy)
+				}
+			},
+		},
+		{scenario: "exit code",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					name        string
+					policy      exitPolicy
+					run         testrun
+					regressions []durationRegression
+					result      int
+				}{
+					{name: "default/all passed",
+						run:    testrun{numTests: 2, numPassed: 2, percentPassed: 100},
+						result: exitOK,
+					},
+					{name: "default/failed",
+						run:    testrun{numTests: 2, numPassed: 1, numFailed: 1, percentPassed: 50},
+						result: exitFailed,
+					},
+					{name: "default/package failed",
+						run:    testrun{numTests: 1, numPassed: 1, numPackageFailed: 1, percentPassed: 100},
+						result: exitFailed,
+					},
+					{name: "max failed/package failed",
+						policy: exitPolicy{maxFailed: 1},
+						run:    testrun{numTests: 2, numPassed: 1, numFailed: 1, numPackageFailed: 1, percentPassed: 50},
+						result: exitFailed,
+					},
+					{name: "default/skipped",
+						run:    testrun{numTests: 2, numPassed: 1, numSkipped: 1, percentPassed: 50},
+						result: exitOK,
+					},
+					{name: "default/no tests",
+						run:    testrun{},
+						result: exitOK,
+					},
+					{name: "default/build failed",
+						run:    testrun{numBuildFailed: 1},
+						result: exitFailed,
+					},
+					{name: "max failed/build failed",
+						policy: exitPolicy{maxFailed: 1},
+						run:    testrun{numTests: 2, numPassed: 1, numFailed: 1, numBuildFailed: 1, percentPassed: 50},
+						result: exitFailed,
+					},
+					{name: "max failed/no maximum/build failed",
+						policy: exitPolicy{maxFailed: -1},
+						run:    testrun{numBuildFailed: 1},
+						result: exitOK,
+					},
+					{name: "max failed/not exceeded",
+						policy: exitPolicy{maxFailed: 1},
+						run:    testrun{numTests: 2, numPassed: 1, numFailed: 1, percentPassed: 50},
+						result: exitOK,
+					},
+					{name: "max failed/exceeded",
+						policy: exitPolicy{maxFailed: 1},
+						run:    testrun{numTests: 3, numPassed: 1, numFailed: 2, percentPassed: 33},
+						result: exitFailed,
+					},
+					{name: "max failed/no maximum",
+						policy: exitPolicy{maxFailed: -1},
+						run:    testrun{numTests: 2, numFailed: 2},
+						result: exitOK,
+					},
+					{name: "min pass rate/satisfied",
+						policy: exitPolicy{maxFailed: -1, minPassRate: 50},
+						run:    testrun{numTests: 2, numPassed: 1, numFailed: 1, percentPassed: 50},
+						result: exitOK,
+					},
+					{name: "min pass rate/not satisfied",
+						policy: exitPolicy{maxFailed: -1, minPassRate: 51},
+						run:    testrun{numTests: 2, numPassed: 1, numFailed: 1, percentPassed: 50},
+						result: exitPassRate,
+					},
+					{name: "fail on skip",
+						policy: exitPolicy{failOnSkip: true},
+						run:    testrun{numTests: 2, numPassed: 1, numSkipped: 1, percentPassed: 50},
+						result: exitSkipped,
+					},
+					{name: "default/flaky",
+						run:    testrun{numTests: 2, numPassed: 2, numFlaky: 1, percentPassed: 100},
+						result: exitOK,
+					},
+					{name: "fail on flaky",
+						policy: exitPolicy{failOnFlaky: true},
+						run:    testrun{numTests: 2, numPassed: 2, numFlaky: 1, percentPassed: 100},
+						result: exitFlaky,
+					},
+					{name: "default/regressions",
+						run:         testrun{numTests: 1, numPassed: 1, percentPassed: 100},
+						regressions: []durationRegression{{packageName: "pkg", elapsed: 2 * time.Second}},
+						result:      exitOK,
+					},
+					{name: "fail on regression",
+						policy:      exitPolicy{failOnRegression: true},
+						run:         testrun{numTests: 1, numPassed: 1, percentPassed: 100},
+						regressions: []durationRegression{{packageName: "pkg", elapsed: 2 * time.Second}},
+						result:      exitRegression,
+					},
+					{name: "fail on regression/failed",
+						policy:      exitPolicy{failOnRegression: true},
+						run:         testrun{numTests: 1, numFailed: 1},
+						regressions: []durationRegression{{packageName: "pkg", elapsed: 2 * time.Second}},
+						result:      exitFailed,
+					},
+					{name: "fail on no tests",
+						policy: exitPolicy{failOnNoTests: true},
+						run:    testrun{},
+						result: exitNoTests,
+					},
+					{name: "fail on build failure",
+						policy: exitPolicy{failOnBuildFailure: true, failOnNoTests: true},
+						run:    testrun{numBuildFailed: 1},
+						result: exitBuildFailed,
+					},
+				}
+				for _, tc := range testcases {
+					t.Run(tc.name, func(t *testing.T) {
+						// ACT
+						result := tc.policy.exitCode(&tc.run, tc.regressions)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+					})
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
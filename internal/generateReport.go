@@ -5,6 +5,9 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // reportMode is the mode of the report to generate.
@@ -22,6 +25,43 @@ const (
 	rmSummaryOnly
 )
 
+// reportFormat is the format of the report to generate.
+//
+//	rfMarkdown   // markdown (GFM)
+//	rfJUnit      // JUnit XML
+//	rfHTML       // self-contained html
+//	rfJSON       // json
+//
+// The zero-value is rfMarkdown.
+type reportFormat int
+
+const (
+	rfMarkdown reportFormat = iota //
+	rfJUnit
+	rfHTML
+	rfJSON
+)
+
+// reportFormats maps the names accepted by the -format option to the
+// corresponding reportFormat.
+var reportFormats = map[string]reportFormat{
+	"markdown": rfMarkdown,
+	"md":       rfMarkdown,
+	"junit":    rfJUnit,
+	"html":     rfHTML,
+	"json":     rfJSON,
+}
+
+// defaultFilename returns the default output filename for the format.
+func (f reportFormat) defaultFilename() string {
+	return map[reportFormat]string{
+		rfMarkdown: "test-report.md",
+		rfJUnit:    "test-report.xml",
+		rfHTML:     "test-report.html",
+		rfJSON:     "test-report.json",
+	}[f]
+}
+
 // function variables to facilitate testing
 var (
 	osCreate   = os.Create
@@ -32,15 +72,52 @@ var (
 	mdExport = func(md *markdown, w io.Writer) error {
 		return md.export(w)
 	}
+	junitExport = func(j *junit, w io.Writer) error {
+		return j.export(w)
+	}
+	htmlExport = func(h *htmlReport, w io.Writer) error {
+		return h.export(w)
+	}
+	jsonExport = func(j *jsonReport, w io.Writer) error {
+		return j.export(w)
+	}
 )
 
 // generateReport is a command that generates a report.
+//
+// The report is generated from the files identified by inputs (file paths
+// or glob patterns) or, if no inputs are specified, from piped stdin.
 type generateReport struct {
-	title    string
-	mode     reportMode
-	filename string
-	parser   interface {
+	title        string
+	mode         reportMode
+	format       reportFormat
+	thresholds   thresholds
+	exitPolicy   exitPolicy
+	filename     string
+	sourceURL    string               // URL template for links to source references (see sourceLinks)
+	moduleRoot   string               // the directory containing the go.mod of the module tested
+	snippetLines int                  // the number of lines of source code context around source references
+	coverProfile string               // a coverage profile from which to compute statement-weighted coverage
+	collapse     bool                 // true to write test output and packages in collapsible elements (markdown only)
+	maxLines     int                  // the maximum number of lines of each block of output (markdown only; 0: no limit)
+	maxSize      int                  // the maximum size of the report in bytes (markdown only; 0: 1 MiB; < 0: no limit)
+	github       bool                 // true to append the report to the GitHub Actions job summary and annotate failures
+	baseline     string               // a go test -json log or json report with which to compare the testrun
+	changes      changes              // resolved from baseline when the report is written (nil: no baseline)
+	regression   regressionThreshold  // the increase in elapsed time, compared with the baseline, that is a regression
+	regressions  []durationRegression // resolved from baseline when the report is written
+	history      string               // a history file to which the testrun is appended (and from which trends are reported)
+	historyRuns  int                  // the number of runs presented in a trend (0: defaultHistoryRuns)
+	trend        []historyEntry       // resolved from history when the report is written (nil: no history)
+	slowest      int                  // the number of slowest tests and packages to report (markdown only; 0: none)
+	budget       time.Duration        // the duration budget of a test; tests exceeding the budget are reported (markdown only)
+	links        *sourceLinks         // resolved from sourceURL and moduleRoot when the report is written
+	snippets     *snippets            // resolved from snippetLines and moduleRoot when the report is written
+	module       *module              // loaded from moduleRoot when the report is written
+	inputs       []string
+	parser       interface {
 		parse(io.Reader, *testrun) error
+		parseFiles([]string, *testrun) error
 	}
 }
 
@@ -51,20 +128,65 @@ func (generateReport) checkError(err error) bool {
 		return true
 	}
 	fmt.Println("ERROR:", err)
-	osExit(-2)
+	osExit(exitError)
 	return false // in testing osExit is mocked so we need a valid return
 }
 
 // Run is a method that generates a report.
 func (cmd generateReport) Run(opts *Options) int {
-	if !cmd.checkError(cmd.checkPipe()) {
+	td := &testrun{}
+	if !cmd.checkError(cmd.read(td)) {
 		return 1
 	}
+	return cmd.write(td)
+}
 
-	td := &testrun{}
-	if !cmd.checkError(cmd.parser.parse(os.Stdin, td)) {
+// write writes the report for a testrun to the output file of the command,
+// returning the exit code for the testrun according to the exit policy of
+// the command.  If a coverage profile is specified, the coverage of the
+// testrun is computed from the profile.  If a baseline is specified, the
+// testrun is compared with the baseline (including the elapsed times of
+// tests and packages; see durationRegressions).  If a history file is
+// specified, the testrun is appended to the history once the report is
+// written.
+//
+// In GitHub Actions mode, a markdown report is also appended to the job
+// summary and failures are annotated (see appendStepSummary and annotate).
+func (cmd generateReport) write(td *testrun) int {
+	if cmd.coverProfile != "" {
+		cp, err := loadCoverProfile(cmd.coverProfile)
+		if !cmd.checkError(err) {
+			return 1
+		}
+		cp.apply(td)
+	}
+
+	if cmd.baseline != "" {
+		base, err := loadBaseline(cmd.baseline)
+		if !cmd.checkError(err) {
+			return 1
+		}
+		cmd.changes = compare(base, td)
+		cmd.regressions = durationRegressions(base, td, cmd.regression)
+	}
+
+	var entry historyEntry
+	if cmd.history != "" {
+		history, err := loadHistory(cmd.history)
+		if !cmd.checkError(err) {
+			return 1
+		}
+		entry = newHistoryEntry(td)
+		cmd.trend = trend(history, entry, coalesce(cmd.historyRuns, defaultHistoryRuns))
+	}
+
+	mod, err := loadModule(cmd.moduleRoot)
+	if !cmd.checkError(err) {
 		return 1
 	}
+	cmd.module = mod
+	cmd.links = newSourceLinks(cmd.sourceURL, mod)
+	cmd.snippets = newSnippets(cmd.snippetLines, mod)
 
 	output, err := osCreate(cmd.filename)
 	if !cmd.checkError(err) {
@@ -72,19 +194,113 @@ func (cmd generateReport) Run(opts *Options) int {
 	}
 	defer output.Close()
 
-	md := &markdown{
-		title:   cmd.title,
-		mode:    cmd.mode,
-		testrun: td,
-	}
-	if !cmd.checkError(mdExport(md, output)) {
+	if !cmd.checkError(cmd.export(td, output)) {
 		return 1
 	}
 
-	return map[bool]int{
-		true:  -1,
-		false: 0,
-	}[td.numFailed > 0]
+	if cmd.github {
+		if !cmd.checkError(cmd.appendStepSummary(td)) {
+			return 1
+		}
+		annotate(td, mod)
+	}
+
+	if cmd.history != "" {
+		if !cmd.checkError(appendHistory(cmd.history, entry)) {
+			return 1
+		}
+	}
+
+	return cmd.exitPolicy.exitCode(td, cmd.regressions)
+}
+
+// read parses the input to the command into a testrun.  If no inputs are
+// specified, stdin is parsed (which must be piped); otherwise the files
+// identified by the inputs are parsed as a single stream, producing a
+// single, merged testrun.
+func (cmd generateReport) read(td *testrun) error {
+	if len(cmd.inputs) == 0 {
+		if err := cmd.checkPipe(); err != nil {
+			return err
+		}
+		return cmd.parser.parse(os.Stdin, td)
+	}
+
+	filenames, err := inputFiles(cmd.inputs)
+	if err != nil {
+		return err
+	}
+
+	return cmd.parser.parseFiles(filenames, td)
+}
+
+// inputFiles returns the names of the files identified by the specified
+// inputs.  Each input is either a file path or a glob pattern; a pattern
+// that does not match any files is an error.  An input that is not a
+// pattern is returned as-is (if the file does not exist this will
+// result in an error when the file is opened).
+func inputFiles(inputs []string) ([]string, error) {
+	filenames := []string{}
+	for _, input := range inputs {
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, input)
+		}
+		switch {
+		case len(matches) > 0:
+			filenames = append(filenames, matches...)
+		case !strings.ContainsAny(input, "*?["):
+			filenames = append(filenames, input)
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrNoInputFiles, input)
+		}
+	}
+	return filenames, nil
+}
+
+// export writes the report for a testrun to the specified writer in the
+// format of the command.
+func (cmd generateReport) export(td *testrun, w io.Writer) error {
+	switch cmd.format {
+	case rfJUnit:
+		return junitExport(&junit{title: cmd.title, testrun: td}, w)
+	case rfHTML:
+		return htmlExport(&htmlReport{
+			title:      cmd.title,
+			mode:       cmd.mode,
+			thresholds: &cmd.thresholds,
+			links:      cmd.links,
+			snippets:   cmd.snippets,
+			module:     cmd.module,
+			testrun:    td,
+		}, w)
+	case rfJSON:
+		return jsonExport(&jsonReport{title: cmd.title, testrun: td}, w)
+	default:
+		return mdExport(cmd.markdown(td), w)
+	}
+}
+
+// markdown returns a markdown report writer for a testrun, configured
+// with the options of the command.
+func (cmd generateReport) markdown(td *testrun) *markdown {
+	return &markdown{
+		title:       cmd.title,
+		mode:        cmd.mode,
+		thresholds:  &cmd.thresholds,
+		links:       cmd.links,
+		snippets:    cmd.snippets,
+		module:      cmd.module,
+		collapse:    cmd.collapse,
+		maxLines:    cmd.maxLines,
+		maxSize:     cmd.maxSize,
+		changes:     cmd.changes,
+		regressions: cmd.regressions,
+		trend:       cmd.trend,
+		slowest:     cmd.slowest,
+		budget:      cmd.budget,
+		testrun:     td,
+	}
 }
 
 // checkPipe is a method that checks if the program is being piped input.
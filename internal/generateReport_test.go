@@ -2,10 +2,13 @@ package internal
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/blugnu/test"
 )
@@ -16,6 +19,10 @@ func (fake fakeParser) parse(r io.Reader, tr *testrun) error {
 	return fake.Err
 }
 
+func (fake fakeParser) parseFiles(filenames []string, tr *testrun) error {
+	return fake.Err
+}
+
 func Test_osFileMode(t *testing.T) {
 	// there are no meaningful tests for this function;
 	// we exercise the code for coverage, for which we need
@@ -44,6 +51,42 @@ func Test_mdExport(t *testing.T) {
 	_ = mdExport(md, w)
 }
 
+func Test_junitExport(t *testing.T) {
+	// there are no meaningful tests for this function;
+	// we exercise the code for coverage, for which we need
+	// a valid junit object and a valid writer
+
+	j := &junit{testrun: &testrun{}}
+	w := io.Discard
+
+	// we don't care about the return value
+	_ = junitExport(j, w)
+}
+
+func Test_htmlExport(t *testing.T) {
+	// there are no meaningful tests for this function;
+	// we exercise the code for coverage, for which we need
+	// a valid html report object and a valid writer
+
+	h := &htmlReport{testrun: &testrun{}}
+	w := io.Discard
+
+	// we don't care about the return value
+	_ = htmlExport(h, w)
+}
+
+func Test_jsonExport(t *testing.T) {
+	// there are no meaningful tests for this function;
+	// we exercise the code for coverage, for which we need
+	// a valid json report object and a valid writer
+
+	j := &jsonReport{testrun: &testrun{}}
+	w := io.Discard
+
+	// we don't care about the return value
+	_ = jsonExport(j, w)
+}
+
 func TestCheckError(t *testing.T) {
 	// ARRANGE
 	callsOSExit := false
@@ -118,6 +161,71 @@ func TestCheckPipe(t *testing.T) {
 	}
 }
 
+func TestInputFiles(t *testing.T) {
+	// ARRANGE
+	dir := t.TempDir()
+	for _, name := range []string{"unit-a.json", "unit-b.json", "other.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("error creating test file: %s", err)
+		}
+	}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "paths",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := inputFiles([]string{"a.json", "b.json"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, result).Equals([]string{"a.json", "b.json"})
+			},
+		},
+		{scenario: "pattern",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := inputFiles([]string{filepath.Join(dir, "unit-*.json"), "other.json"})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, result).Equals([]string{
+					filepath.Join(dir, "unit-a.json"),
+					filepath.Join(dir, "unit-b.json"),
+					"other.json",
+				})
+			},
+		},
+		{scenario: "pattern with no matches",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := inputFiles([]string{filepath.Join(dir, "none-*.json")})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrNoInputFiles)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "invalid pattern",
+			exec: func(t *testing.T) {
+				// ACT
+				result, err := inputFiles([]string{"[.json"})
+
+				// ASSERT
+				test.Error(t, err).Is(filepath.ErrBadPattern)
+				test.That(t, result).IsNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
 func TestGenerateReportRun(t *testing.T) {
 	// ARRANGE
 	exitCode := 0
@@ -143,6 +251,67 @@ func TestGenerateReportRun(t *testing.T) {
 				test.That(t, exitCode).Equals(-2)
 			},
 		},
+		{scenario: "input file not found",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &generateReport{
+					inputs: []string{filepath.Join(t.TempDir(), "missing.json")},
+					parser: fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "input file pattern with no matches",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				sut := &generateReport{
+					inputs: []string{filepath.Join(t.TempDir(), "*.json")},
+					parser: fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "success/multiple input files",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				dir := t.TempDir()
+				_ = os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"Action":"start","Package":"a"}`), 0o644)
+				_ = os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"Action":"start","Package":"b"}`), 0o644)
+
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				var packages []string
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					for _, p := range md.packages {
+						packages = append(packages, p.name)
+					}
+					return nil
+				})()
+
+				sut := &generateReport{
+					inputs: []string{filepath.Join(dir, "*.json")},
+					parser: &parser{},
+				}
+
+				// ACT
+				result := sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, result).Equals(0)
+				test.Strings(t, packages).Equals([]string{"a", "b"})
+			},
+		},
 		{scenario: "parser error",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -162,6 +331,223 @@ func TestGenerateReportRun(t *testing.T) {
 				test.That(t, exitCode).Equals(-2)
 			},
 		},
+		{scenario: "coverage profile error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return nil, errors.New("read error")
+				})()
+
+				sut := &generateReport{
+					coverProfile: "cover.out",
+					parser:       fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "success/coverage profile",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osReadFile, func(name string) ([]byte, error) {
+					if name == "cover.out" {
+						return []byte("mode: set\ngithub.com/foo/pkg/foo.go:1.1,2.2 4 1\ngithub.com/foo/pkg/foo.go:3.1,4.2 1 0\n"), nil
+					}
+					return nil, fs.ErrNotExist
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				var coverage *float64
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					coverage = md.testrun.coverage
+					return nil
+				})()
+
+				sut := &generateReport{
+					coverProfile: "cover.out",
+					parser:       fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.IsTrue(t, coverage != nil && *coverage == 80, "coverage from profile")
+			},
+		},
+		{scenario: "baseline error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+
+				sut := &generateReport{
+					baseline: filepath.Join(t.TempDir(), "missing.json"),
+					parser:   fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "success/baseline",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				var changes changes
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					changes = md.changes
+					return nil
+				})()
+				baseline := filepath.Join(t.TempDir(), "baseline.json")
+				_ = os.WriteFile(baseline, []byte(`{"schema": 1, "packages": [{"name": "pkg", "tests": [{"name": "Test1", "result": "passed"}]}]}`), 0o644)
+
+				sut := &generateReport{
+					baseline: baseline,
+					parser:   fakeParser{},
+				}
+
+				// ACT
+				result := sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, result).Equals(0)
+				test.That(t, changes.count()).Equals(1)
+				test.That(t, len(changes[tcRemoved])).Equals(1)
+			},
+		},
+		{scenario: "regressions",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					policy exitPolicy
+					result int
+				}{
+					{policy: exitPolicy{}, result: 0},
+					{policy: exitPolicy{failOnRegression: true}, result: -7},
+					{policy: exitPolicy{failOnRegression: true, failOnSkip: true}, result: -4},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%+v", tc.policy), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&osCreate, func(name string) (*os.File, error) {
+							return &os.File{}, nil
+						})()
+						var regressions []durationRegression
+						defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+							regressions = md.regressions
+							return nil
+						})()
+						baseline := filepath.Join(t.TempDir(), "baseline.json")
+						_ = os.WriteFile(baseline, []byte(`{"schema": 1, "packages": [{"name": "pkg", "elapsed": 1, "tests": [{"name": "Test1", "result": "passed", "elapsed": 1}]}]}`), 0o644)
+						td := &testrun{packages: []*packageinfo{
+							{name: "pkg", passed: true, elapsed: 5 * time.Second, tests: []*testinfo{
+								{path: "Test1", result: trPassed, elapsed: 1 * time.Second},
+								{path: "Test2", result: trSkipped},
+							}},
+						}}
+						td.recount()
+
+						sut := &generateReport{
+							baseline:   baseline,
+							regression: defaultRegressionThreshold,
+							exitPolicy: tc.policy,
+						}
+
+						// ACT
+						result := sut.write(td)
+
+						// ASSERT
+						test.That(t, result).Equals(tc.result)
+						test.That(t, regressions).Equals([]durationRegression{
+							{packageName: "pkg", baseline: 1 * time.Second, elapsed: 5 * time.Second},
+						})
+					})
+				}
+			},
+		},
+		{scenario: "history error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				history := filepath.Join(t.TempDir(), "history.jsonl")
+				_ = os.WriteFile(history, []byte("not json\n"), 0o644)
+
+				sut := &generateReport{
+					history: history,
+					parser:  fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "success/history",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				defer test.Using(&osGetenv, func(string) string { return "" })()
+				var trend []historyEntry
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					trend = md.trend
+					return nil
+				})()
+				history := filepath.Join(t.TempDir(), "history.jsonl")
+				_ = os.WriteFile(history, []byte(`{"commit":"abc","tests":10,"passed":10,"percentPassed":100}`+"\n"), 0o644)
+
+				sut := &generateReport{
+					history: history,
+					parser:  fakeParser{},
+				}
+
+				// ACT
+				result := sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, result).Equals(0)
+				test.That(t, len(trend)).Equals(2)
+				test.That(t, trend[0].Commit).Equals("abc")
+
+				entries, err := loadHistory(history)
+				test.Error(t, err).IsNil()
+				test.That(t, len(entries)).Equals(2)
+			},
+		},
 		{scenario: "file creation error",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -209,6 +595,144 @@ func TestGenerateReportRun(t *testing.T) {
 				test.That(t, exitCode).Equals(-2)
 			},
 		},
+		{scenario: "junit export error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				defer test.Using(&junitExport, func(j *junit, w io.Writer) error {
+					return errors.New("junit export error")
+				})()
+
+				sut := &generateReport{
+					format: rfJUnit,
+					parser: fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "html export error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				defer test.Using(&htmlExport, func(h *htmlReport, w io.Writer) error {
+					return errors.New("html export error")
+				})()
+
+				sut := &generateReport{
+					format: rfHTML,
+					parser: fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "json export error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				defer test.Using(&jsonExport, func(j *jsonReport, w io.Writer) error {
+					return errors.New("json export error")
+				})()
+
+				sut := &generateReport{
+					format: rfJSON,
+					parser: fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "step summary error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					return nil
+				})()
+				defer test.Using(&osGetenv, func(string) string { return "" })()
+
+				sut := &generateReport{
+					github: true,
+					parser: fakeParser{},
+				}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "success/github",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				exports := 0
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					exports++
+					return nil
+				})()
+				summary := filepath.Join(t.TempDir(), "summary.md")
+				defer test.Using(&osGetenv, func(name string) string {
+					return map[string]string{"GITHUB_STEP_SUMMARY": summary}[name]
+				})()
+
+				sut := &generateReport{
+					github: true,
+					parser: fakeParser{},
+				}
+
+				// ACT
+				result := sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, result).Equals(0)
+				test.That(t, exports).Equals(2)
+			},
+		},
 		{scenario: "success/all tests passed",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -260,6 +784,36 @@ func TestGenerateReportRun(t *testing.T) {
 				test.That(t, result).Equals(-1)
 			},
 		},
+		{scenario: "success/exit policy",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Stdin, os.Stdout)() // we just need a non-nil *os.File
+				defer test.Using(&osFileMode, func(file fs.FileInfo) fs.FileMode {
+					return os.ModeNamedPipe
+				})()
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					md.testrun.numTests = 2
+					md.testrun.numPassed = 1
+					md.testrun.numFailed = 1
+					md.testrun.percentPassed = 50
+					return nil
+				})()
+
+				sut := &generateReport{
+					exitPolicy: exitPolicy{maxFailed: 1, minPassRate: 60},
+					parser:     fakeParser{},
+				}
+
+				// ACT
+				result := sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, result).Equals(exitPassRate)
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
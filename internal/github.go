@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// osOpenFile is a function variable to facilitate testing.
+var osOpenFile = os.OpenFile
+
+// appendStepSummary appends a markdown report for a testrun to the job
+// summary of a GitHub Actions step (the file identified by the
+// GITHUB_STEP_SUMMARY environment variable), regardless of the format of
+// the report written to the output file.
+func (cmd generateReport) appendStepSummary(td *testrun) error {
+	filename := osGetenv("GITHUB_STEP_SUMMARY")
+	if filename == "" {
+		return ErrNoStepSummary
+	}
+
+	file, err := osOpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return mdExport(cmd.markdown(td), file)
+}
+
+// annotate writes a GitHub Actions workflow command (::error) for each
+// source reference in the output of each failed test, and for the panic
+// (if any) in each failed test, so that failures are shown as annotations
+// on the source files (e.g. in the diff of a pull request).
+//
+// Files are identified relative to the repository root, resolved using the
+// module tested.  If a file cannot be resolved (e.g. there is no module, or
+// the file does not exist in the module, such as testing.go referenced in
+// the output of a data race) the annotation identifies only the test.
+func annotate(td *testrun, mod *module) {
+	file := func(pkg, file string) string {
+		if mod == nil {
+			return ""
+		}
+		if file = mod.file(pkg, file); file == "" {
+			return ""
+		}
+		return path.Join(mod.repoDir, file)
+	}
+
+	for _, p := range td.packages {
+		for _, t := range p.tests {
+			if t.result != trFailed {
+				continue
+			}
+
+			refs := []string{}
+			for ref := range t.output {
+				refs = append(refs, ref)
+			}
+			slices.Sort(refs)
+
+			for _, ref := range refs {
+				f, line, ok := splitRef(ref)
+				if !ok {
+					continue
+				}
+				fmt.Println(workflowCommand("error", file(t.packageName, f), line, t.path, strings.Join(t.output[ref], "\n")))
+			}
+
+			if t.panic != nil {
+				f, line := "", ""
+				if i := focusFrame(t.panic.stack, mod, t.packageName); i != -1 {
+					frame := t.panic.stack[i]
+					f = file(frame.packageName(), path.Base(filepath.ToSlash(frame.file)))
+					line = fmt.Sprint(frame.line)
+				}
+				msg := "panic: " + t.panic.message
+				if t.panic.timeout {
+					msg = t.panic.message
+				}
+				fmt.Println(workflowCommand("error", f, line, t.path, msg))
+			}
+		}
+	}
+}
+
+// workflowCommand returns a GitHub Actions workflow command (e.g. "error")
+// annotating a line in a file with a title and message.  The file and line
+// are omitted if the file is not specified.
+//
+// The message and properties are escaped as required by the workflow
+// command syntax.
+func workflowCommand(cmd, file, line, title, msg string) string {
+	data := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	prop := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+	props := []string{}
+	if file != "" {
+		props = append(props, "file="+prop.Replace(file))
+		if line != "" {
+			props = append(props, "line="+prop.Replace(line))
+		}
+	}
+	props = append(props, "title="+prop.Replace(title))
+
+	return fmt.Sprintf("::%s %s::%s", cmd, strings.Join(props, ","), data.Replace(msg))
+}
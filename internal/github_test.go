@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestGitHub(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "appendStepSummary/not set",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osGetenv, func(string) string { return "" })()
+
+				// ACT
+				err := generateReport{}.appendStepSummary(&testrun{})
+
+				// ASSERT
+				test.Error(t, err).Is(ErrNoStepSummary)
+			},
+		},
+		{scenario: "appendStepSummary/open error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				operr := errors.New("open error")
+				defer test.Using(&osGetenv, func(string) string { return "summary.md" })()
+				defer test.Using(&osOpenFile, func(string, int, os.FileMode) (*os.File, error) { return nil, operr })()
+
+				// ACT
+				err := generateReport{}.appendStepSummary(&testrun{})
+
+				// ASSERT
+				test.Error(t, err).Is(operr)
+			},
+		},
+		{scenario: "appendStepSummary/appended",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				filename := filepath.Join(t.TempDir(), "summary.md")
+				_ = os.WriteFile(filename, []byte("existing\n"), 0o644)
+				defer test.Using(&osGetenv, func(string) string { return filename })()
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					_, err := io.WriteString(w, md.title+"\n")
+					return err
+				})()
+
+				// ACT
+				err := generateReport{title: "Test Report", format: rfJSON}.appendStepSummary(&testrun{})
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				b, _ := os.ReadFile(filename)
+				test.That(t, string(b)).Equals("existing\nTest Report\n")
+			},
+		},
+		{scenario: "annotate",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osStat, func(string) (fs.FileInfo, error) { return nil, nil })()
+				mod := &module{path: "github.com/foo/mod", repoDir: "mod"}
+				tr := &testrun{packages: []*packageinfo{
+					{name: "github.com/foo/mod/pkg", tests: []*testinfo{
+						{path: "TestPassed", packageName: "github.com/foo/mod/pkg", result: trPassed,
+							output: map[string][]string{"foo_test.go:5": {"passed"}},
+						},
+						{path: "TestFailed", packageName: "github.com/foo/mod/pkg", result: trFailed,
+							output: map[string][]string{
+								"":               {"no reference"},
+								"foo_test.go:12": {"expected: 1", "got: 2"},
+								"bar_test.go:3":  {"100% wrong"},
+							},
+						},
+						{path: "TestPanic", packageName: "github.com/foo/mod/pkg", result: trFailed,
+							panic: &panicinfo{message: "runtime error", stack: []stackframe{
+								{function: "runtime.panic", file: "/go/src/runtime/panic.go", line: 1},
+								{function: "github.com/foo/mod/pkg.foo", file: "/src/mod/pkg/foo.go", line: 20},
+							}},
+						},
+						{path: "TestTimeout", packageName: "github.com/foo/mod/pkg", result: trFailed,
+							panic: &panicinfo{message: "test timed out after 1s", timeout: true},
+						},
+					}},
+				}}
+
+				// ACT
+				stdout, _ := test.CaptureOutput(t, func() {
+					annotate(tr, mod)
+				})
+
+				// ASSERT
+				stdout.Equals([]string{
+					"::error file=mod/pkg/bar_test.go,line=3,title=TestFailed::100%25 wrong",
+					"::error file=mod/pkg/foo_test.go,line=12,title=TestFailed::expected: 1%0Agot: 2",
+					"::error file=mod/pkg/foo.go,line=20,title=TestPanic::panic: runtime error",
+					"::error title=TestTimeout::test timed out after 1s",
+				})
+			},
+		},
+		{scenario: "annotate/file not in module",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osStat, func(name string) (fs.FileInfo, error) {
+					if filepath.Base(name) == "testing.go" {
+						return nil, fs.ErrNotExist
+					}
+					return nil, nil
+				})()
+				mod := &module{path: "github.com/foo/mod", dir: "/src/mod", repoDir: "mod"}
+				tr := &testrun{packages: []*packageinfo{
+					{name: "github.com/foo/mod/pkg", tests: []*testinfo{
+						{path: "TestRace", packageName: "github.com/foo/mod/pkg", result: trFailed,
+							output: map[string][]string{
+								"testing.go:1865": {"race detected during execution of test"},
+								"race_test.go:12": {"failed"},
+							},
+						},
+					}},
+				}}
+
+				// ACT
+				stdout, _ := test.CaptureOutput(t, func() {
+					annotate(tr, mod)
+				})
+
+				// ASSERT
+				stdout.Equals([]string{
+					"::error file=mod/pkg/race_test.go,line=12,title=TestRace::failed",
+					"::error title=TestRace::race detected during execution of test",
+				})
+			},
+		},
+		{scenario: "annotate/no module",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tr := &testrun{packages: []*packageinfo{
+					{name: "pkg", tests: []*testinfo{
+						{path: "TestFailed/a,b", packageName: "pkg", result: trFailed,
+							output: map[string][]string{"foo_test.go:12": {"failed"}},
+						},
+					}},
+				}}
+
+				// ACT
+				stdout, _ := test.CaptureOutput(t, func() {
+					annotate(tr, nil)
+				})
+
+				// ASSERT
+				stdout.Equals([]string{
+					"::error title=TestFailed/a%2Cb::failed",
+				})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// defaultHistoryRuns is the number of runs presented in a trend if not
+// specified.
+const defaultHistoryRuns = 10
+
+// timeNow is a function variable to facilitate testing.
+var timeNow = time.Now
+
+// historyEntry is a snapshot of a testrun in a history file.  A history
+// file is an append-only file of entries, one json object per line.
+//
+// Entries are keyed by commit and branch (identified by the GITHUB_SHA and
+// GITHUB_REF_NAME environment variables); an entry for a commit and branch
+// supersedes any earlier entry for the same commit and branch (e.g. when a
+// workflow is re-run).  The elapsed time is in seconds.
+type historyEntry struct {
+	Time          time.Time `json:"time"`
+	Commit        string    `json:"commit,omitempty"`
+	Branch        string    `json:"branch,omitempty"`
+	Tests         int       `json:"tests"`
+	Passed        int       `json:"passed"`
+	Failed        int       `json:"failed"`
+	Skipped       int       `json:"skipped"`
+	PercentPassed int       `json:"percentPassed"`
+	Elapsed       float64   `json:"elapsed"`
+}
+
+// newHistoryEntry returns the history entry for a testrun.
+func newHistoryEntry(td *testrun) historyEntry {
+	return historyEntry{
+		Time:          timeNow().UTC(),
+		Commit:        osGetenv("GITHUB_SHA"),
+		Branch:        osGetenv("GITHUB_REF_NAME"),
+		Tests:         td.numTests,
+		Passed:        td.numPassed,
+		Failed:        td.numFailed,
+		Skipped:       td.numSkipped,
+		PercentPassed: td.percentPassed,
+		Elapsed:       td.elapsed.Seconds(),
+	}
+}
+
+// loadHistory reads the entries in a history file, in the order they were
+// appended.  It is not an error if the file does not exist (there is no
+// history).
+func loadHistory(filename string) ([]historyEntry, error) {
+	b, err := osReadFile(filename)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	entries := []historyEntry{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		e := historyEntry{}
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("%w: %s: line %d: %w", ErrInvalidHistory, filename, n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// appendHistory appends an entry to a history file, creating the file if
+// it does not exist.
+func appendHistory(filename string, e historyEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	file, err := osOpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(b, '\n'))
+	return err
+}
+
+// trend returns the most recent n entries in a history for the branch of
+// an entry, ending with (and including) that entry.  If the entry does not
+// identify a branch, entries for all branches are included.  Entries that
+// are superseded by a later entry for the same commit and branch are
+// excluded.
+func trend(history []historyEntry, current historyEntry, n int) []historyEntry {
+	type key struct{ commit, branch string }
+
+	entries := append(append([]historyEntry{}, history...), current)
+	latest := map[key]int{}
+	for i, e := range entries {
+		if e.Commit != "" {
+			latest[key{e.Commit, e.Branch}] = i
+		}
+	}
+
+	result := []historyEntry{}
+	for i, e := range entries {
+		if current.Branch != "" && e.Branch != current.Branch {
+			continue
+		}
+		if j, ok := latest[key{e.Commit, e.Branch}]; ok && j != i {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result[max(0, len(result)-n):]
+}
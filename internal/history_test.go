@@ -0,0 +1,228 @@
+package internal
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestHistory(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "load/file not found",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) { return nil, fs.ErrNotExist })()
+
+				// ACT
+				result, err := loadHistory("history.jsonl")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(result)).Equals(0)
+			},
+		},
+		{scenario: "load/read error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				readerr := errors.New("read error")
+				defer test.Using(&osReadFile, func(string) ([]byte, error) { return nil, readerr })()
+
+				// ACT
+				_, err := loadHistory("history.jsonl")
+
+				// ASSERT
+				test.Error(t, err).Is(readerr)
+			},
+		},
+		{scenario: "load/invalid entry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte("{\"tests\": 1}\nnot json\n"), nil
+				})()
+
+				// ACT
+				_, err := loadHistory("history.jsonl")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidHistory)
+				test.Strings(t, []string{err.Error()}).Contains([]string{"history.jsonl: line 2"})
+			},
+		},
+		{scenario: "load/entries",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte(`{"time": "2024-01-02T03:04:05Z", "commit": "abc", "branch": "main", "tests": 10, "passed": 9, "failed": 1, "percentPassed": 90, "elapsed": 1.5}
+
+{"commit": "def", "tests": 11}
+`), nil
+				})()
+
+				// ACT
+				result, err := loadHistory("history.jsonl")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).Equals([]historyEntry{
+					{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Commit: "abc", Branch: "main", Tests: 10, Passed: 9, Failed: 1, PercentPassed: 90, Elapsed: 1.5},
+					{Commit: "def", Tests: 11},
+				})
+			},
+		},
+		{scenario: "append",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				filename := filepath.Join(t.TempDir(), "history.jsonl")
+				e1 := historyEntry{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Commit: "abc", Tests: 10, Passed: 10, PercentPassed: 100}
+				e2 := historyEntry{Time: time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC), Commit: "def", Tests: 11, Passed: 10, Failed: 1, PercentPassed: 90}
+
+				// ACT
+				err1 := appendHistory(filename, e1)
+				err2 := appendHistory(filename, e2)
+
+				// ASSERT
+				test.Error(t, err1).IsNil()
+				test.Error(t, err2).IsNil()
+				b, _ := os.ReadFile(filename)
+				test.Strings(t, b).Equals([]string{
+					`{"time":"2024-01-02T03:04:05Z","commit":"abc","tests":10,"passed":10,"failed":0,"skipped":0,"percentPassed":100,"elapsed":0}`,
+					`{"time":"2024-01-03T03:04:05Z","commit":"def","tests":11,"passed":10,"failed":1,"skipped":0,"percentPassed":90,"elapsed":0}`,
+					``,
+				})
+			},
+		},
+		{scenario: "append/open error",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				operr := errors.New("open error")
+				defer test.Using(&osOpenFile, func(string, int, os.FileMode) (*os.File, error) { return nil, operr })()
+
+				// ACT
+				err := appendHistory("history.jsonl", historyEntry{})
+
+				// ASSERT
+				test.Error(t, err).Is(operr)
+			},
+		},
+		{scenario: "new entry",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&timeNow, func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) })()
+				defer test.Using(&osGetenv, func(name string) string {
+					return map[string]string{"GITHUB_SHA": "abc", "GITHUB_REF_NAME": "main"}[name]
+				})()
+				td := &testrun{
+					elapsed:       2500 * time.Millisecond,
+					numTests:      4,
+					numPassed:     2,
+					numFailed:     1,
+					numSkipped:    1,
+					percentPassed: 50,
+				}
+
+				// ACT
+				result := newHistoryEntry(td)
+
+				// ASSERT
+				test.That(t, result).Equals(historyEntry{
+					Time:          time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+					Commit:        "abc",
+					Branch:        "main",
+					Tests:         4,
+					Passed:        2,
+					Failed:        1,
+					Skipped:       1,
+					PercentPassed: 50,
+					Elapsed:       2.5,
+				})
+			},
+		},
+		{scenario: "trend/branch",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				history := []historyEntry{
+					{Commit: "a", Branch: "main"},
+					{Commit: "b", Branch: "feature"},
+					{Commit: "c", Branch: "main"},
+				}
+
+				// ACT
+				result := trend(history, historyEntry{Commit: "d", Branch: "main"}, 10)
+
+				// ASSERT
+				test.That(t, result).Equals([]historyEntry{
+					{Commit: "a", Branch: "main"},
+					{Commit: "c", Branch: "main"},
+					{Commit: "d", Branch: "main"},
+				})
+			},
+		},
+		{scenario: "trend/no branch",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				history := []historyEntry{
+					{Commit: "a", Branch: "main"},
+					{Commit: "b", Branch: "feature"},
+				}
+
+				// ACT
+				result := trend(history, historyEntry{Tests: 1}, 10)
+
+				// ASSERT
+				test.That(t, result).Equals([]historyEntry{
+					{Commit: "a", Branch: "main"},
+					{Commit: "b", Branch: "feature"},
+					{Tests: 1},
+				})
+			},
+		},
+		{scenario: "trend/superseded",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				history := []historyEntry{
+					{Commit: "a", Branch: "main", Tests: 1},
+					{Commit: "b", Branch: "main", Tests: 2},
+					{Commit: "a", Branch: "main", Tests: 3},
+					{Tests: 4},
+					{Tests: 5},
+				}
+
+				// ACT
+				result := trend(history, historyEntry{Commit: "b", Branch: "main", Tests: 6}, 10)
+
+				// ASSERT
+				test.That(t, result).Equals([]historyEntry{
+					{Commit: "a", Branch: "main", Tests: 3},
+					{Commit: "b", Branch: "main", Tests: 6},
+				})
+			},
+		},
+		{scenario: "trend/limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				history := []historyEntry{{Tests: 1}, {Tests: 2}, {Tests: 3}}
+
+				// ACT
+				result := trend(history, historyEntry{Tests: 4}, 2)
+
+				// ASSERT
+				test.That(t, result).Equals([]historyEntry{{Tests: 3}, {Tests: 4}})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// htmlStyle is the stylesheet embedded in the html report.
+const htmlStyle = `body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+table { border-collapse: collapse; }
+td { padding: 4px 8px; vertical-align: top; }
+.summary td { border: 1px solid #d0d7de; }
+.right { text-align: right; }
+.filter { margin: 1em 0; }
+.filter label { margin-right: 1em; }
+details.package { border: 1px solid #d0d7de; border-radius: 6px; margin-bottom: 0.5em; padding: 0.5em; }
+details.package > summary { cursor: pointer; }
+details.package table { width: 100%; margin-top: 0.5em; }
+.elapsed { color: #656d76; text-align: right; white-space: nowrap; }
+.coverage { color: #656d76; margin-left: 1em; }
+.conflict { color: #9a6700; }
+.ref { font-style: italic; margin-top: 0.5em; }
+pre.snippet { border-left: 3px solid #d0d7de; }
+pre.snippet mark { background: #fff8c5; }
+details.panic { margin-top: 0.5em; }
+details.panic > summary { color: #cf222e; cursor: pointer; }
+pre.stack mark { background: #ffebe9; }
+pre { background: #f6f8fa; padding: 0.5em; margin: 0.25em 0; overflow-x: auto; }
+footer { margin-top: 2em; color: #656d76; font-size: smaller; }`
+
+// htmlScript is the script embedded in the html report to filter the tests
+// shown according to the result filters selected.
+const htmlScript = `function applyFilter() {
+  const show = new Set();
+  document.querySelectorAll(".filter input:checked").forEach(cb => show.add(cb.value));
+  document.querySelectorAll("details.package:not(.build-failed)").forEach(pkg => {
+    let visible = 0;
+    pkg.querySelectorAll("tr.test").forEach(tr => {
+      const on = show.has(tr.dataset.result);
+      tr.style.display = on ? "" : "none";
+      visible += on ? 1 : 0;
+    });
+    pkg.style.display = visible > 0 ? "" : "none";
+  });
+}
+document.querySelectorAll(".filter input").forEach(cb => cb.addEventListener("change", applyFilter));
+applyFilter();`
+
+// htmlReport is a self-contained html report writer.
+type htmlReport struct {
+	title      string
+	mode       reportMode
+	thresholds *thresholds  // pass rate thresholds for the report icon (nil: defaultThresholds)
+	links      *sourceLinks // links for source references in test output (nil: not linked)
+	snippets   *snippets    // source code around source references in test output (nil: no snippets)
+	module     *module      // the module tested, identifying the frames of interest in a panic (nil: the package)
+	*IndentWriter
+	*testrun
+}
+
+// export produces an html report to the specified writer.
+func (h *htmlReport) export(w io.Writer) error {
+	h.IndentWriter = &IndentWriter{output: w}
+
+	icon := markdown{thresholds: h.thresholds, testrun: h.testrun}.getReportIcon()
+	h.WriteLn("<!DOCTYPE html>")
+	h.WriteXMLElement(func() {
+		h.WriteXMLElement(func() {
+			h.WriteLn("<meta charset='utf-8'>")
+			h.WriteLn("<title>%s</title>", html.EscapeString(h.title))
+			h.WriteXMLElement(func() { h.WriteLn(htmlStyle) }, "style")
+		}, "head")
+		h.WriteXMLElement(func() {
+			h.WriteLn("<h1>%s&nbsp;&nbsp;%s</h1>", icon, html.EscapeString(h.title))
+			h.writeSummary(icon)
+			if h.mode != rmSummaryOnly && len(h.packages) > 0 {
+				h.writeFilter()
+				for _, p := range h.packages {
+					h.writePackage(p)
+				}
+				h.WriteXMLElement(func() { h.WriteLn(htmlScript) }, "script")
+			}
+			h.WriteLn("<footer>html test report generated by <a href='https://github.com/blugnu/test-report'>https://github.com/blugnu/test-report</a></footer>")
+		}, "body")
+	}, "html", "lang='en'")
+
+	return h.error
+}
+
+// writeSummary writes the summary table of the html report.
+func (h htmlReport) writeSummary(reportIcon string) {
+	writeRow := func(i string, s string, v string) {
+		h.WriteXMLElement(func() {
+			h.WriteLn("<td colspan='3' class='right'>%s</td>", i)
+			h.WriteLn("<td>%s</td>", s)
+			h.WriteLn("<td class='right'>%s</td>", v)
+		}, "tr")
+	}
+
+	h.WriteXMLElement(func() {
+		h.WriteXMLElement(func() {
+			h.WriteLn("<td><b>packages</b></td>")
+			h.WriteLn("<td>%d</td>", len(h.packages))
+			h.WriteLn("<td>%s</td>", h.elapsed)
+			h.WriteLn("<td><b>tests</b></td>")
+			h.WriteLn("<td class='right'>%d</td>", h.numTests)
+		}, "tr")
+		if h.numBuildFailed > 0 {
+			writeRow(icon.noEntry, "build failed", fmt.Sprintf("%d", h.numBuildFailed))
+		}
+		if h.numPackageFailed > 0 {
+			writeRow(icon.redDot, "package failed", fmt.Sprintf("%d", h.numPackageFailed))
+		}
+		if h.numFailed > 0 {
+			writeRow(icon.redDot, "failed", fmt.Sprintf("%d", h.numFailed))
+		}
+		if h.numRaces > 0 {
+			writeRow(icon.race, "data races", fmt.Sprintf("%d", h.numRaces))
+		}
+		if h.numSkipped > 0 {
+			writeRow(icon.mutedBell, "skipped", fmt.Sprintf("%d", h.numSkipped))
+		}
+		if h.numConflicts > 0 {
+			writeRow(icon.warning, "conflicting results", fmt.Sprintf("%d", h.numConflicts))
+		}
+		writeRow(reportIcon, "passed", fmt.Sprintf("%d%%", h.percentPassed))
+		if h.coverage != nil {
+			writeRow(icon.barChart, "coverage", fmt.Sprintf("%.1f%%", *h.coverage))
+		}
+	}, "table", "class='summary'")
+}
+
+// writeFilter writes the checkboxes used to filter the tests shown in the
+// report by result.  In rmFailedTests mode only failed tests are initially
+// shown; in rmAllTests mode all tests are initially shown.
+func (h htmlReport) writeFilter() {
+	checked := map[bool]string{true: " checked", false: ""}
+	all := h.mode == rmAllTests
+
+	h.WriteXMLElement(func() {
+		h.WriteLn("<label><input type='checkbox' value='failed'%s> %s failed</label>", checked[true], icon.redDot)
+		h.WriteLn("<label><input type='checkbox' value='skipped'%s> %s skipped</label>", checked[all], icon.mutedBell)
+		h.WriteLn("<label><input type='checkbox' value='passed'%s> %s passed</label>", checked[all], icon.greenTick)
+	}, "div", "class='filter'")
+}
+
+// writePackage writes a collapsible section for a package, containing a
+// table of all tests in the package.  The section is initially expanded
+// only if the package did not pass.
+//
+// If the package failed to build, the build output is written in place
+// of any tests.
+func (h htmlReport) writePackage(p *packageinfo) {
+	pkgicon := map[bool]string{
+		true:  icon.greenTick,
+		false: icon.redDot,
+	}[p.passed]
+	open := map[bool]string{
+		true:  "",
+		false: " open",
+	}
+
+	if p.buildFailed {
+		h.WriteXMLElement(func() {
+			h.WriteLn("<summary>%s <b>%s</b> build failed</summary>", icon.noEntry, html.EscapeString(p.name))
+			lines := make([]string, 0, len(p.buildOutput))
+			for _, s := range p.buildOutput {
+				lines = append(lines, html.EscapeString(s))
+			}
+			h.Write("<pre>%s</pre>", strings.Join(lines, "\n"))
+			h.WriteLn()
+		}, "details", "class='package build-failed' open")
+		return
+	}
+
+	h.WriteXMLElement(func() {
+		coverage := ""
+		if p.coverage != nil {
+			coverage = fmt.Sprintf(" <span class='coverage'>%.1f%% coverage</span>", *p.coverage)
+		}
+		h.WriteLn("<summary>%s <b>%s</b>%s <span class='elapsed'>%s</span></summary>", pkgicon, html.EscapeString(p.name), coverage, p.elapsed)
+		h.WriteXMLElement(func() {
+			if p.failed || p.panic != nil {
+				h.writePackageFailure(p)
+			}
+			h.writeTests(p)
+		}, "table")
+	}, "details", "class='package'"+open[p.passed])
+}
+
+// writePackageFailure writes a synthetic "package failure" row for a
+// package that failed without any failed test (e.g. due to an error in
+// TestMain or a panic in an init() function), with any output of the
+// package not associated with a test and any panic in the package.  The
+// row is filtered as a failed test.
+func (h htmlReport) writePackageFailure(p *packageinfo) {
+	h.WriteXMLElement(func() {
+		h.WriteLn("<td>%s</td>", icon.redDot)
+		h.WriteXMLElement(func() {
+			h.WriteLn("<b>package failure</b>")
+			if len(p.output) > 0 {
+				lines := make([]string, 0, len(p.output))
+				for _, s := range p.output {
+					lines = append(lines, html.EscapeString(s))
+				}
+				h.Write("<pre>%s</pre>", strings.Join(lines, "\n"))
+				h.WriteLn()
+			}
+			if p.panic != nil {
+				h.writePanic(p.name, p.panic)
+			}
+		}, "td")
+		h.WriteLn("<td class='elapsed'></td>")
+	}, "tr", "class='test'", "data-result='failed'")
+}
+
+// writeTests writes a table row for each test in a package.  Each row has
+// a data-result attribute identifying the test result, used to filter the
+// tests shown.
+func (h htmlReport) writeTests(p *packageinfo) {
+	testicon := map[testResult]string{
+		trPassed:  icon.greenTick,
+		trFailed:  icon.redDot,
+		trSkipped: icon.mutedBell,
+	}
+	for _, t := range p.tests {
+		h.WriteXMLElement(func() {
+			h.WriteLn("<td>%s</td>", testicon[t.result])
+			h.WriteXMLElement(func() {
+				h.WriteLn("<b>%s</b>", html.EscapeString(t.path))
+				if t.conflict {
+					h.WriteLn("<div class='conflict'>%s conflicting results in merged reports</div>", icon.warning)
+				}
+				h.writeOutput(t)
+				if t.panic != nil {
+					h.writePanic(t.packageName, t.panic)
+				}
+			}, "td")
+			h.WriteLn("<td class='elapsed'>%s</td>", t.elapsed)
+		}, "tr", "class='test'", fmt.Sprintf("data-result='%s'", t.result))
+	}
+}
+
+// writeOutput writes the output of a test.  Each source in the output is
+// written with the source reference, followed by the output associated
+// with that source in a <pre> element.
+func (h htmlReport) writeOutput(t *testinfo) {
+	keys := []string{}
+	for k := range t.output {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, ref := range keys {
+		log := t.output[ref]
+		lines := make([]string, 0, len(log))
+		for _, s := range log {
+			lines = append(lines, html.EscapeString(strings.TrimSuffix(s, "\n")))
+		}
+		switch url := h.links.link(t.packageName, ref); {
+		case url != "":
+			h.WriteLn("<div class='ref'><a href='%s'>%s</a></div>", html.EscapeString(url), html.EscapeString(ref))
+		case ref != "":
+			h.WriteLn("<div class='ref'>%s</div>", html.EscapeString(ref))
+		}
+		if snippet := h.snippets.snippet(t.packageName, ref); snippet != nil {
+			h.writeSnippet(snippet)
+		}
+		h.Write("<pre>%s</pre>", strings.Join(lines, "\n"))
+		h.WriteLn()
+	}
+}
+
+// writeSnippet writes a snippet of source code in a <pre> element, with
+// each line preceded by the line number.  The line identified by the
+// source reference is highlighted.
+func (h htmlReport) writeSnippet(snippet []snippetLine) {
+	width := len(strconv.Itoa(snippet[len(snippet)-1].number))
+	lines := make([]string, 0, len(snippet))
+	for _, l := range snippet {
+		s := html.EscapeString(fmt.Sprintf("%*d  %s", width, l.number, l.text))
+		if l.ref {
+			s = "<mark>" + s + "</mark>"
+		}
+		lines = append(lines, s)
+	}
+	h.Write("<pre class='snippet'>%s</pre>", strings.Join(lines, "\n"))
+	h.WriteLn()
+}
+
+// writePanic writes a collapsible section for a panic (or test timeout) in
+// a package, containing the stack of the goroutine that panicked.  The
+// first frame in the module tested (or the package, if the module is not
+// known) is highlighted and preceded by a snippet of the source at that
+// location (if snippets are enabled).
+func (h htmlReport) writePanic(pkg string, pi *panicinfo) {
+	summary := fmt.Sprintf("%s panic: %s", icon.explosion, html.EscapeString(pi.message))
+	if pi.timeout {
+		summary = fmt.Sprintf("%s %s", icon.hourglass, html.EscapeString(pi.message))
+	}
+
+	focus := focusFrame(pi.stack, h.module, pkg)
+	h.WriteXMLElement(func() {
+		h.WriteLn("<summary>%s</summary>", summary)
+		if focus != -1 {
+			f := pi.stack[focus]
+			if snippet := h.snippets.snippet(f.packageName(), f.ref()); snippet != nil {
+				h.writeSnippet(snippet)
+			}
+		}
+		lines := make([]string, 0, len(pi.stack))
+		for i, f := range pi.stack {
+			s := html.EscapeString(fmt.Sprintf("%s\n    %s:%d", f.function, f.file, f.line))
+			if i == focus {
+				s = "<mark>" + s + "</mark>"
+			}
+			lines = append(lines, s)
+		}
+		h.Write("<pre class='stack'>%s</pre>", strings.Join(lines, "\n"))
+		h.WriteLn()
+	}, "details", "class='panic'")
+}
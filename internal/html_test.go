@@ -0,0 +1,391 @@
+package internal
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestHTML(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		// filter tests
+		{scenario: "filter/failed tests mode",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+
+				// ACT
+				h.writeFilter()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<div class='filter'>",
+					"  <label><input type='checkbox' value='failed' checked> 🔴 failed</label>",
+					"  <label><input type='checkbox' value='skipped'> 🔕 skipped</label>",
+					"  <label><input type='checkbox' value='passed'> ✅ passed</label>",
+					"</div>",
+					"",
+				})
+			},
+		},
+		{scenario: "filter/all tests mode",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					mode:         rmAllTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+
+				// ACT
+				h.writeFilter()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<div class='filter'>",
+					"  <label><input type='checkbox' value='failed' checked> 🔴 failed</label>",
+					"  <label><input type='checkbox' value='skipped' checked> 🔕 skipped</label>",
+					"  <label><input type='checkbox' value='passed' checked> ✅ passed</label>",
+					"</div>",
+					"",
+				})
+			},
+		},
+
+		// output tests
+		{scenario: "output/2 sources, 1 with 2 lines of output",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				output := map[string][]string{
+					"filename_test.go:12": {
+						"expected: <nil>",
+						"  got: err",
+					},
+					"filename_test.go:14": {
+						"second output",
+					},
+				}
+
+				// ACT
+				h.writeOutput(&testinfo{output: output})
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<div class='ref'>filename_test.go:12</div>",
+					"<pre>expected: &lt;nil&gt;",
+					"  got: err</pre>",
+					"<div class='ref'>filename_test.go:14</div>",
+					"<pre>second output</pre>",
+					"",
+				})
+			},
+		},
+
+		{scenario: "output/linked source",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osStat, func(string) (fs.FileInfo, error) { return nil, nil })()
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					links: &sourceLinks{
+						url:    "https://example.com/{path}?line={line}&plain=1",
+						module: &module{path: "github.com/foo/repo"},
+					},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				ti := &testinfo{
+					packageName: "github.com/foo/repo",
+					output:      map[string][]string{"filename_test.go:12": {"output"}},
+				}
+
+				// ACT
+				h.writeOutput(ti)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<div class='ref'><a href='https://example.com/filename_test.go?line=12&amp;plain=1'>filename_test.go:12</a></div>",
+					"<pre>output</pre>",
+					"",
+				})
+			},
+		},
+
+		{scenario: "output/snippet",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				snippet := []snippetLine{
+					{number: 9, text: "if got != want {"},
+					{number: 10, text: "    t.Errorf(\"got <%v>\", got)", ref: true},
+					{number: 11, text: "}"},
+				}
+
+				// ACT
+				h.writeSnippet(snippet)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<pre class='snippet'> 9  if got != want {",
+					"<mark>10      t.Errorf(&#34;got &lt;%v&gt;&#34;, got)</mark>",
+					"11  }</pre>",
+					"",
+				})
+			},
+		},
+
+		{scenario: "output/panic",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pi := &panicinfo{
+					message: "index out of range [1] with length 1",
+					stack: []stackframe{
+						{function: "runtime.goPanicIndex", file: "/go/src/runtime/panic.go", line: 1},
+						{function: "github.com/foo/package.TestFoo", file: "/src/package/foo_test.go", line: 12},
+					},
+				}
+
+				// ACT
+				h.writePanic("github.com/foo/package", pi)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<details class='panic'>",
+					"  <summary>💥 panic: index out of range [1] with length 1</summary>",
+					"  <pre class='stack'>runtime.goPanicIndex",
+					"    /go/src/runtime/panic.go:1",
+					"<mark>github.com/foo/package.TestFoo",
+					"    /src/package/foo_test.go:12</mark></pre>",
+					"</details>",
+					"",
+				})
+			},
+		},
+
+		// package tests
+		{scenario: "package/failed package, 2 tests, 1 failed, 1 passed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:    "github.com/foo/package",
+					elapsed: 3 * time.Millisecond,
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond},
+						{path: "Test2", result: trPassed, elapsed: 2 * time.Millisecond},
+					},
+				}
+
+				// ACT
+				h.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<details class='package' open>",
+					"  <summary>🔴 <b>github.com/foo/package</b> <span class='elapsed'>3ms</span></summary>",
+					"  <table>",
+					"    <tr class='test' data-result='failed'>",
+					"      <td>🔴</td>",
+					"      <td>",
+					"        <b>Test1</b>",
+					"      </td>",
+					"      <td class='elapsed'>1ms</td>",
+					"    </tr>",
+					"    <tr class='test' data-result='passed'>",
+					"      <td>✅</td>",
+					"      <td>",
+					"        <b>Test2</b>",
+					"      </td>",
+					"      <td class='elapsed'>2ms</td>",
+					"    </tr>",
+					"  </table>",
+					"</details>",
+					"",
+				})
+			},
+		},
+		{scenario: "package/package failure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:    "github.com/foo/package",
+					elapsed: 1 * time.Millisecond,
+					failed:  true,
+					output:  []string{"TestMain: <setup> failed"},
+				}
+
+				// ACT
+				h.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<details class='package' open>",
+					"  <summary>🔴 <b>github.com/foo/package</b> <span class='elapsed'>1ms</span></summary>",
+					"  <table>",
+					"    <tr class='test' data-result='failed'>",
+					"      <td>🔴</td>",
+					"      <td>",
+					"        <b>package failure</b>",
+					"        <pre>TestMain: &lt;setup&gt; failed</pre>",
+					"      </td>",
+					"      <td class='elapsed'></td>",
+					"    </tr>",
+					"  </table>",
+					"</details>",
+					"",
+				})
+			},
+		},
+		{scenario: "package/passed package",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:   "github.com/foo/package",
+					passed: true,
+				}
+
+				// ACT
+				h.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Contains("<details class='package'>")
+			},
+		},
+		{scenario: "package/build failed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:        "github.com/foo/package",
+					buildFailed: true,
+					buildOutput: []string{"foo_test.go:6:14: cannot use \"s\" as int value"},
+				}
+
+				// ACT
+				h.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<details class='package build-failed' open>",
+					"  <summary>⛔ <b>github.com/foo/package</b> build failed</summary>",
+					"  <pre>foo_test.go:6:14: cannot use &#34;s&#34; as int value</pre>",
+					"</details>",
+					"",
+				})
+			},
+		},
+
+		// export tests
+		{scenario: "export/summary only",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					mode:    rmSummaryOnly,
+					title:   "Tests & Results",
+					testrun: &testrun{},
+				}
+				h.testrun.elapsed = 6 * time.Millisecond
+				h.testrun.packages = []*packageinfo{{name: "github.com/foo/package"}}
+				h.testrun.numTests = 2
+				h.testrun.numFailed = 1
+				h.testrun.numPassed = 1
+				h.testrun.percentPassed = 50
+
+				// ACT
+				err := h.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				result := test.Strings(t, buf.Bytes())
+				result.Contains("<title>Tests &amp; Results</title>")
+				result.Contains("<h1>📕&nbsp;&nbsp;Tests &amp; Results</h1>")
+				result.Contains([]string{
+					"<tr>",
+					"<td colspan='3' class='right'>🔴</td>",
+					"<td>failed</td>",
+					"<td class='right'>1</td>",
+					"</tr>",
+				})
+				test.IsFalse(t, bytes.Contains(buf.Bytes(), []byte("<details")), "contains package details")
+				test.IsFalse(t, bytes.Contains(buf.Bytes(), []byte("<script>")), "contains script")
+			},
+		},
+		{scenario: "export/failed tests mode",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				h := &htmlReport{
+					mode:    rmFailedTests,
+					title:   "Test Report",
+					testrun: &testrun{},
+				}
+				h.testrun.packages = []*packageinfo{{
+					name: "github.com/foo/package",
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed},
+					},
+				}}
+				h.testrun.numTests = 1
+				h.testrun.numFailed = 1
+
+				// ACT
+				err := h.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				result := test.Strings(t, buf.Bytes())
+				result.Contains("<!DOCTYPE html>")
+				result.Contains("<style>")
+				result.Contains("<div class='filter'>")
+				result.Contains("<details class='package' open>")
+				result.Contains("<script>")
+				result.Contains("</html>")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonSchemaVersion is the version of the schema of the json report.  It is
+// incremented if any change is made to the schema that is not backwards
+// compatible.
+const jsonSchemaVersion = 1
+
+// jsonTestrun is the root object of a json report.  All elapsed times are
+// in seconds.  coverage (of the testrun and each package) is the %age of
+// statements covered, omitted if not reported.  races is the number of data
+// races detected (in all tests and packages).
+type jsonTestrun struct {
+	Schema        int           `json:"schema"`
+	Title         string        `json:"title"`
+	Elapsed       float64       `json:"elapsed"`
+	Tests         int           `json:"tests"`
+	Passed        int           `json:"passed"`
+	Failed        int           `json:"failed"`
+	Skipped       int           `json:"skipped"`
+	PercentPassed int           `json:"percentPassed"`
+	Coverage      *float64      `json:"coverage,omitempty"`
+	BuildFailed   int           `json:"buildFailed,omitempty"`
+	Conflicts     int           `json:"conflicts,omitempty"`
+	Flaky         int           `json:"flaky,omitempty"`
+	Races         int           `json:"races,omitempty"`
+	PackageFailed int           `json:"packageFailed,omitempty"`
+	Packages      []jsonPackage `json:"packages"`
+}
+
+// jsonPackage is a package in a json report.  If the package failed to
+// build, buildFailed is true and buildOutput contains the output of the
+// build (e.g. compiler errors).  failed is true if the package failed
+// without any failed test (e.g. due to an error in TestMain).  output is
+// any output not associated with a test; panic and races are any panic and
+// data races in the package that could not be attributed to a test.
+type jsonPackage struct {
+	Name        string          `json:"name"`
+	Passed      bool            `json:"passed"`
+	Elapsed     float64         `json:"elapsed"`
+	Coverage    *float64        `json:"coverage,omitempty"`
+	BuildFailed bool            `json:"buildFailed,omitempty"`
+	BuildOutput []string        `json:"buildOutput,omitempty"`
+	Failed      bool            `json:"failed,omitempty"`
+	Output      []string        `json:"output,omitempty"`
+	Tests       []jsonTest      `json:"tests"`
+	Benchmarks  []jsonBenchmark `json:"benchmarks,omitempty"`
+	Panic       *jsonPanic      `json:"panic,omitempty"`
+	Races       []jsonRace      `json:"races,omitempty"`
+}
+
+// jsonBenchmark is a benchmark in a json report.  bytesPerOp and allocsPerOp
+// are omitted if not reported; metrics contains any other metrics reported
+// (e.g. MB/s or custom metrics), keyed by unit.
+type jsonBenchmark struct {
+	Name        string             `json:"name"`
+	Failed      bool               `json:"failed,omitempty"`
+	Iterations  int                `json:"iterations"`
+	NsPerOp     float64            `json:"nsPerOp"`
+	BytesPerOp  *float64           `json:"bytesPerOp,omitempty"`
+	AllocsPerOp *float64           `json:"allocsPerOp,omitempty"`
+	Metrics     map[string]float64 `json:"metrics,omitempty"`
+}
+
+// jsonTest is a test in a json report.  The output of the test is keyed by
+// source reference ("<filename>:<line #>"), with output not associated with
+// any source reference keyed by an empty string.  started is the (wall-clock)
+// time the test started, if recorded, and parallel is true if the test was
+// paused to run in parallel with other tests.  conflict is true if the
+// test had different results in merged reports.  attempts is the result of
+// each attempt of a test run more than once (the result of the test is that
+// of the final attempt).  panic is any panic (or timeout) that occurred in
+// the test and races are any data races detected during the test.
+type jsonTest struct {
+	Name     string              `json:"name"`
+	Result   string              `json:"result"`
+	Elapsed  float64             `json:"elapsed"`
+	Started  string              `json:"started,omitempty"`
+	Parallel bool                `json:"parallel,omitempty"`
+	Output   map[string][]string `json:"output,omitempty"`
+	Conflict bool                `json:"conflict,omitempty"`
+	Attempts []string            `json:"attempts,omitempty"`
+	Panic    *jsonPanic          `json:"panic,omitempty"`
+	Races    []jsonRace          `json:"races,omitempty"`
+}
+
+// jsonPanic is a panic (or test timeout) in a json report.  running
+// identifies the tests running when a timeout occurred; stack is the stack
+// of the goroutine that panicked (or, for a timeout, running the test).
+type jsonPanic struct {
+	Message string           `json:"message"`
+	Timeout bool             `json:"timeout,omitempty"`
+	Running []string         `json:"running,omitempty"`
+	Stack   []jsonStackframe `json:"stack"`
+}
+
+// jsonRace is a data race in a json report.  accesses are the conflicting
+// memory accesses (the most recent first) and goroutines the sites at which
+// the goroutines involved were created.
+type jsonRace struct {
+	Accesses   []jsonRaceStack `json:"accesses"`
+	Goroutines []jsonRaceStack `json:"goroutines,omitempty"`
+}
+
+// jsonRaceStack is a memory access (or the creation of a goroutine) in a
+// data race in a json report, e.g. "Read at 0x00c0000182b8 by goroutine 8".
+type jsonRaceStack struct {
+	Description string           `json:"description"`
+	Stack       []jsonStackframe `json:"stack"`
+}
+
+// jsonStackframe is a frame in the stack of a panic in a json report.
+type jsonStackframe struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// newJSONPanic returns the jsonPanic for a panicinfo (nil if there is no
+// panic).
+func newJSONPanic(pi *panicinfo) *jsonPanic {
+	if pi == nil {
+		return nil
+	}
+	return &jsonPanic{
+		Message: pi.message,
+		Timeout: pi.timeout,
+		Running: pi.running,
+		Stack:   newJSONStack(pi.stack),
+	}
+}
+
+// newJSONStack returns the jsonStackframes for a stack.
+func newJSONStack(stack []stackframe) []jsonStackframe {
+	result := make([]jsonStackframe, 0, len(stack))
+	for _, f := range stack {
+		result = append(result, jsonStackframe{Function: f.function, File: f.file, Line: f.line})
+	}
+	return result
+}
+
+// newJSONRaces returns the jsonRaces for the data races in a test or
+// package (nil if there are none).
+func newJSONRaces(races []*raceinfo) []jsonRace {
+	stacks := func(rs []racestack) []jsonRaceStack {
+		var result []jsonRaceStack
+		for _, s := range rs {
+			result = append(result, jsonRaceStack{Description: s.description, Stack: newJSONStack(s.stack)})
+		}
+		return result
+	}
+
+	var result []jsonRace
+	for _, r := range races {
+		result = append(result, jsonRace{Accesses: stacks(r.accesses), Goroutines: stacks(r.goroutines)})
+	}
+	return result
+}
+
+// jsonReport is a json report writer.
+type jsonReport struct {
+	title string
+	*testrun
+}
+
+// export produces a json report to the specified writer.
+func (j *jsonReport) export(w io.Writer) error {
+	doc := jsonTestrun{
+		Schema:        jsonSchemaVersion,
+		Title:         j.title,
+		Elapsed:       j.elapsed.Seconds(),
+		Tests:         j.numTests,
+		Passed:        j.numPassed,
+		Failed:        j.numFailed,
+		Skipped:       j.numSkipped,
+		PercentPassed: j.percentPassed,
+		Coverage:      j.coverage,
+		BuildFailed:   j.numBuildFailed,
+		Conflicts:     j.numConflicts,
+		Flaky:         j.numFlaky,
+		Races:         j.numRaces,
+		PackageFailed: j.numPackageFailed,
+		Packages:      make([]jsonPackage, 0, len(j.packages)),
+	}
+	for _, p := range j.packages {
+		pkg := jsonPackage{
+			Name:        p.name,
+			Passed:      p.passed,
+			Elapsed:     p.elapsed.Seconds(),
+			Coverage:    p.coverage,
+			BuildFailed: p.buildFailed,
+			BuildOutput: p.buildOutput,
+			Failed:      p.failed,
+			Output:      p.output,
+			Panic:       newJSONPanic(p.panic),
+			Races:       newJSONRaces(p.races),
+			Tests:       make([]jsonTest, 0, len(p.tests)),
+		}
+		for _, t := range p.tests {
+			var attempts []string
+			for _, r := range t.attempts {
+				attempts = append(attempts, r.String())
+			}
+			var started string
+			if !t.started.IsZero() {
+				started = t.started.Format(time.RFC3339Nano)
+			}
+			pkg.Tests = append(pkg.Tests, jsonTest{
+				Name:     t.path,
+				Result:   t.result.String(),
+				Elapsed:  t.elapsed.Seconds(),
+				Started:  started,
+				Parallel: t.parallel,
+				Output:   t.output,
+				Conflict: t.conflict,
+				Attempts: attempts,
+				Panic:    newJSONPanic(t.panic),
+				Races:    newJSONRaces(t.races),
+			})
+		}
+		for _, b := range p.benchmarks {
+			pkg.Benchmarks = append(pkg.Benchmarks, jsonBenchmark{
+				Name:        b.name,
+				Failed:      b.failed,
+				Iterations:  b.iterations,
+				NsPerOp:     b.nsPerOp,
+				BytesPerOp:  b.bytesPerOp,
+				AllocsPerOp: b.allocsPerOp,
+				Metrics:     b.metrics,
+			})
+		}
+		doc.Packages = append(doc.Packages, pkg)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,277 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestJSON(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "export/no packages",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				j := &jsonReport{
+					title:   "Test Report",
+					testrun: &testrun{},
+				}
+
+				// ACT
+				err := j.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					`{`,
+					`  "schema": 1,`,
+					`  "title": "Test Report",`,
+					`  "elapsed": 0,`,
+					`  "tests": 0,`,
+					`  "passed": 0,`,
+					`  "failed": 0,`,
+					`  "skipped": 0,`,
+					`  "percentPassed": 0,`,
+					`  "packages": []`,
+					`}`,
+					``,
+				})
+			},
+		},
+		{scenario: "export/1 package, 1 failed test, 1 skipped, 1 passed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				j := &jsonReport{
+					title:   "Test Report",
+					testrun: &testrun{},
+				}
+				j.testrun.elapsed = 6 * time.Millisecond
+				j.testrun.numTests = 3
+				j.testrun.numFailed = 1
+				j.testrun.numSkipped = 1
+				j.testrun.numPassed = 1
+				j.testrun.percentPassed = 33
+				j.testrun.packages = []*packageinfo{{
+					name:    "github.com/foo/package",
+					elapsed: 6 * time.Millisecond,
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond,
+							output: map[string][]string{"foo_test.go:12": {"expected <true>", "got <false>"}},
+						},
+						{path: "Test2", result: trSkipped, elapsed: 2 * time.Millisecond},
+						{path: "Test3", result: trPassed, elapsed: 3 * time.Millisecond},
+					},
+				}}
+
+				// ACT
+				err := j.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					`{`,
+					`  "schema": 1,`,
+					`  "title": "Test Report",`,
+					`  "elapsed": 0.006,`,
+					`  "tests": 3,`,
+					`  "passed": 1,`,
+					`  "failed": 1,`,
+					`  "skipped": 1,`,
+					`  "percentPassed": 33,`,
+					`  "packages": [`,
+					`    {`,
+					`      "name": "github.com/foo/package",`,
+					`      "passed": false,`,
+					`      "elapsed": 0.006,`,
+					`      "tests": [`,
+					`        {`,
+					`          "name": "Test1",`,
+					`          "result": "failed",`,
+					`          "elapsed": 0.001,`,
+					`          "output": {`,
+					`            "foo_test.go:12": [`,
+					`              "expected <true>",`,
+					`              "got <false>"`,
+					`            ]`,
+					`          }`,
+					`        },`,
+					`        {`,
+					`          "name": "Test2",`,
+					`          "result": "skipped",`,
+					`          "elapsed": 0.002`,
+					`        },`,
+					`        {`,
+					`          "name": "Test3",`,
+					`          "result": "passed",`,
+					`          "elapsed": 0.003`,
+					`        }`,
+					`      ]`,
+					`    }`,
+					`  ]`,
+					`}`,
+					``,
+				})
+			},
+		},
+		{scenario: "export/parallel",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				j := &jsonReport{
+					testrun: &testrun{
+						packages: []*packageinfo{{
+							name: "github.com/foo/package",
+							tests: []*testinfo{
+								{path: "Test1", result: trPassed, parallel: true,
+									started: time.Date(2026, 10, 18, 6, 41, 5, 500000000, time.UTC),
+								},
+								{path: "Test2", result: trPassed},
+							},
+						}},
+					},
+				}
+
+				// ACT
+				err := j.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					`          "name": "Test1",`,
+					`          "result": "passed",`,
+					`          "elapsed": 0,`,
+					`          "started": "2026-10-18T06:41:05.5Z",`,
+					`          "parallel": true`,
+					`        },`,
+					`        {`,
+					`          "name": "Test2",`,
+					`          "result": "passed",`,
+					`          "elapsed": 0`,
+				})
+			},
+		},
+		{scenario: "export/panic",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				j := &jsonReport{
+					testrun: &testrun{
+						packages: []*packageinfo{{
+							name: "github.com/foo/package",
+							tests: []*testinfo{
+								{path: "Test1", result: trFailed,
+									panic: &panicinfo{
+										message: "test timed out after 1s",
+										timeout: true,
+										running: []string{"Test1"},
+										stack:   []stackframe{{function: "github.com/foo/package.Test1", file: "/src/foo_test.go", line: 12}},
+									},
+								},
+							},
+						}},
+					},
+				}
+
+				// ACT
+				err := j.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					`          "panic": {`,
+					`            "message": "test timed out after 1s",`,
+					`            "timeout": true,`,
+					`            "running": [`,
+					`              "Test1"`,
+					`            ],`,
+					`            "stack": [`,
+					`              {`,
+					`                "function": "github.com/foo/package.Test1",`,
+					`                "file": "/src/foo_test.go",`,
+					`                "line": 12`,
+					`              }`,
+					`            ]`,
+					`          }`,
+				})
+			},
+		},
+		{scenario: "export/races",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				race := &raceinfo{
+					accesses: []racestack{
+						{description: "Write at 0x00c0000182b8 by goroutine 8", stack: []stackframe{{function: "github.com/foo/package.TestRace.func1", file: "/src/race_test.go", line: 12}}},
+					},
+					goroutines: []racestack{
+						{description: "Goroutine 8 (running) created at", stack: []stackframe{{function: "github.com/foo/package.TestRace", file: "/src/race_test.go", line: 10}}},
+					},
+				}
+				j := &jsonReport{
+					testrun: &testrun{
+						numRaces: 2,
+						packages: []*packageinfo{{
+							name:  "github.com/foo/package",
+							races: []*raceinfo{{accesses: []racestack{{description: "Read at 0x00c0000182b8 by goroutine 7"}}}},
+							tests: []*testinfo{
+								{path: "TestRace", result: trFailed, races: []*raceinfo{race}},
+							},
+						}},
+					},
+				}
+
+				// ACT
+				err := j.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					`  "races": 2,`,
+				})
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					`      "races": [`,
+					`        {`,
+					`          "accesses": [`,
+					`            {`,
+					`              "description": "Read at 0x00c0000182b8 by goroutine 7",`,
+					`              "stack": []`,
+					`            }`,
+					`          ]`,
+					`        }`,
+					`      ]`,
+				})
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					`          "races": [`,
+					`            {`,
+					`              "accesses": [`,
+					`                {`,
+					`                  "description": "Write at 0x00c0000182b8 by goroutine 8",`,
+					`                  "stack": [`,
+					`                    {`,
+					`                      "function": "github.com/foo/package.TestRace.func1",`,
+					`                      "file": "/src/race_test.go",`,
+					`                      "line": 12`,
+					`                    }`,
+					`                  ]`,
+					`                }`,
+					`              ],`,
+					`              "goroutines": [`,
+					`                {`,
+					`                  "description": "Goroutine 8 (running) created at",`,
+				})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// junitTestsuites is the root element of a JUnit XML report.
+type junitTestsuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr,omitempty"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr,omitempty"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestsuite `xml:"testsuite"`
+}
+
+// junitTestsuite is a JUnit XML testsuite element, corresponding to a package.
+// A package that failed to build is reported as a testsuite with an error,
+// with the output of the build in the system-err element.
+type junitTestsuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr,omitempty"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Testcases []junitTestcase `xml:"testcase"`
+	SystemErr string          `xml:"system-err,omitempty"`
+}
+
+// junitTestcase is a JUnit XML testcase element, corresponding to a test.
+type junitTestcase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage is the content of a JUnit XML failure or skipped element.
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// junit is a JUnit XML report writer.
+type junit struct {
+	title string
+	*testrun
+}
+
+// junitTime formats a duration as seconds, as expected by JUnit XML consumers.
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// export produces a JUnit XML report to the specified writer.
+func (j *junit) export(w io.Writer) error {
+	doc := junitTestsuites{
+		Name:     j.title,
+		Tests:    j.numTests,
+		Failures: j.numFailed,
+		Skipped:  j.numSkipped,
+		Time:     junitTime(j.elapsed),
+		Suites:   make([]junitTestsuite, 0, len(j.packages)),
+	}
+	for _, p := range j.packages {
+		ts := j.testsuite(p)
+		doc.Errors += ts.Errors
+		doc.Suites = append(doc.Suites, ts)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// testsuite returns the JUnit testsuite for a package.  A package that
+// failed to build, failed without any failed test or with a panic not
+// attributed to any test is reported as an error, with the build output
+// (or any package output and panic) in the system-err element.  For a test
+// with a panic, the message of the failure is the panic and the text
+// includes the complete output of the panic.
+func (j *junit) testsuite(p *packageinfo) junitTestsuite {
+	ts := junitTestsuite{
+		Name:      p.name,
+		Tests:     len(p.tests),
+		Time:      junitTime(p.elapsed),
+		Testcases: make([]junitTestcase, 0, len(p.tests)),
+	}
+	switch {
+	case p.buildFailed:
+		ts.Errors = 1
+		ts.SystemErr = strings.Join(p.buildOutput, "\n")
+	case p.failed || p.panic != nil:
+		ts.Errors = 1
+		ts.SystemErr = strings.Join(p.output, "\n")
+		if p.panic != nil {
+			ts.SystemErr = strings.TrimPrefix(ts.SystemErr+"\n"+strings.Join(p.panic.output, "\n"), "\n")
+		}
+	}
+	for _, t := range p.tests {
+		tc := junitTestcase{
+			Name:      t.path,
+			Classname: p.name,
+			Time:      junitTime(t.elapsed),
+		}
+
+		msg, text := j.output(t.output)
+		if t.panic != nil {
+			msg = t.panic.output[0]
+			text = strings.TrimPrefix(text+"\n"+strings.Join(t.panic.output, "\n"), "\n")
+		}
+		switch t.result {
+		case trFailed:
+			ts.Failures++
+			tc.Failure = &junitMessage{Message: coalesce(msg, "failed"), Text: text}
+		case trSkipped:
+			ts.Skipped++
+			tc.Skipped = &junitMessage{Message: coalesce(msg, "skipped"), Text: text}
+		default:
+			tc.SystemOut = text
+		}
+		ts.Testcases = append(ts.Testcases, tc)
+	}
+	return ts
+}
+
+// output returns a message and text for the output of a test.  The text
+// contains the output for each source reference, in source reference
+// order, with the first line of each prefixed by the source reference:
+//
+//	<filename>:<line #>: <output line 1>
+//	<output line 2>
+//	...
+//
+// The message is the first line of the text.
+func (j *junit) output(output map[string][]string) (string, string) {
+	keys := []string{}
+	for k := range output {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	lines := []string{}
+	for _, ref := range keys {
+		log := output[ref]
+		if len(log) == 0 {
+			continue
+		}
+		if ref != "" {
+			log = append([]string{ref + ": " + log[0]}, log[1:]...)
+		}
+		for _, s := range log {
+			lines = append(lines, strings.TrimSuffix(s, "\n"))
+		}
+	}
+	if len(lines) == 0 {
+		return "", ""
+	}
+	return lines[0], strings.Join(lines, "\n")
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestJUnit(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "output/no output",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				j := &junit{}
+
+				// ACT
+				msg, text := j.output(map[string][]string{})
+
+				// ASSERT
+				test.That(t, msg).Equals("")
+				test.That(t, text).Equals("")
+			},
+		},
+		{scenario: "output/2 sources, 1 with 2 lines of output",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				j := &junit{}
+				output := map[string][]string{
+					"filename_test.go:14": {"second output"},
+					"filename_test.go:12": {
+						"first output line 1",
+						"first output line 2",
+					},
+				}
+
+				// ACT
+				msg, text := j.output(output)
+
+				// ASSERT
+				test.That(t, msg).Equals("filename_test.go:12: first output line 1")
+				test.That(t, text).Equals("filename_test.go:12: first output line 1\n" +
+					"first output line 2\n" +
+					"filename_test.go:14: second output")
+			},
+		},
+		{scenario: "output/no source reference",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				j := &junit{}
+				output := map[string][]string{
+					"": {"raw output\n"},
+				}
+
+				// ACT
+				msg, text := j.output(output)
+
+				// ASSERT
+				test.That(t, msg).Equals("raw output")
+				test.That(t, text).Equals("raw output")
+			},
+		},
+
+		// testsuite tests
+		{scenario: "testsuite/build failed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				j := &junit{}
+				pkg := &packageinfo{
+					name:        "github.com/foo/package",
+					buildFailed: true,
+					buildOutput: []string{"foo_test.go:6:6: declared and not used: n", "foo_test.go:7:8: undefined: bar"},
+				}
+
+				// ACT
+				result := j.testsuite(pkg)
+
+				// ASSERT
+				test.That(t, result.Errors).Equals(1)
+				test.That(t, result.SystemErr).Equals("foo_test.go:6:6: declared and not used: n\nfoo_test.go:7:8: undefined: bar")
+			},
+		},
+
+		{scenario: "testsuite/panic",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				j := &junit{}
+				pkg := &packageinfo{
+					name: "github.com/foo/package",
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed,
+							output: map[string][]string{"foo_test.go:10": {"output"}},
+							panic:  &panicinfo{output: []string{"panic: oops", "", "goroutine 1 [running]:"}},
+						},
+					},
+				}
+
+				// ACT
+				result := j.testsuite(pkg)
+
+				// ASSERT
+				test.That(t, *result.Testcases[0].Failure).Equals(junitMessage{
+					Message: "panic: oops",
+					Text:    "foo_test.go:10: output\npanic: oops\n\ngoroutine 1 [running]:",
+				})
+			},
+		},
+		{scenario: "testsuite/package failure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				j := &junit{}
+				pkg := &packageinfo{
+					name:   "github.com/foo/package",
+					failed: true,
+					output: []string{"TestMain: setup failed"},
+				}
+
+				// ACT
+				result := j.testsuite(pkg)
+
+				// ASSERT
+				test.That(t, result.Errors).Equals(1)
+				test.That(t, result.SystemErr).Equals("TestMain: setup failed")
+			},
+		},
+		{scenario: "testsuite/package panic",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				j := &junit{}
+				pkg := &packageinfo{
+					name:  "github.com/foo/package",
+					panic: &panicinfo{output: []string{"panic: in init", "", "goroutine 1 [running]:"}},
+				}
+
+				// ACT
+				result := j.testsuite(pkg)
+
+				// ASSERT
+				test.That(t, result.Errors).Equals(1)
+				test.That(t, result.SystemErr).Equals("panic: in init\n\ngoroutine 1 [running]:")
+			},
+		},
+
+		// export tests
+		{scenario: "export/1 package, 1 failed test, 1 skipped, 1 passed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				j := &junit{
+					title:   "Test Report",
+					testrun: &testrun{},
+				}
+				j.testrun.elapsed = 6 * time.Millisecond
+				j.testrun.numTests = 3
+				j.testrun.numFailed = 1
+				j.testrun.numSkipped = 1
+				j.testrun.numPassed = 1
+				j.testrun.percentPassed = 33
+				j.testrun.packages = []*packageinfo{{
+					name:    "github.com/foo/package",
+					elapsed: 6 * time.Millisecond,
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond,
+							output: map[string][]string{"foo_test.go:12": {"expected <true>", "got <false>"}},
+						},
+						{path: "Test2", result: trSkipped, elapsed: 2 * time.Millisecond},
+						{path: "Test3", result: trPassed, elapsed: 3 * time.Millisecond,
+							output: map[string][]string{"foo_test.go:20": {"log & output"}},
+						},
+					},
+				}}
+
+				// ACT
+				err := j.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					`<?xml version="1.0" encoding="UTF-8"?>`,
+					`<testsuites name="Test Report" tests="3" failures="1" skipped="1" time="0.006">`,
+					`  <testsuite name="github.com/foo/package" tests="3" failures="1" skipped="1" time="0.006">`,
+					`    <testcase name="Test1" classname="github.com/foo/package" time="0.001">`,
+					`      <failure message="foo_test.go:12: expected &lt;true&gt;">foo_test.go:12: expected &lt;true&gt;&#xA;got &lt;false&gt;</failure>`,
+					`    </testcase>`,
+					`    <testcase name="Test2" classname="github.com/foo/package" time="0.002">`,
+					`      <skipped message="skipped"></skipped>`,
+					`    </testcase>`,
+					`    <testcase name="Test3" classname="github.com/foo/package" time="0.003">`,
+					`      <system-out>foo_test.go:20: log &amp; output</system-out>`,
+					`    </testcase>`,
+					`  </testsuite>`,
+					`</testsuites>`,
+					``,
+				})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
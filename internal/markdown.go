@@ -1,10 +1,15 @@
 package internal
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // icon is a collection of emoji icons used in the markdown report.
@@ -16,6 +21,18 @@ var icon = struct {
 	redDot     string
 	greenTick  string
 	mutedBell  string
+	noEntry    string
+	warning    string
+	explosion  string
+	hourglass  string
+	race       string
+	barChart   string
+	repeat     string
+	plus       string
+	minus      string
+	dice       string
+	snail      string
+	chartUp    string
 }{
 	redBook:    "📕", // :closed_book:
 	orangeBook: "📙", // :orange_book:
@@ -24,47 +41,190 @@ var icon = struct {
 	redDot:     "🔴", // :red_circle:
 	greenTick:  "✅", // :white_check_mark:
 	mutedBell:  "🔕", // :no_bell:
+	noEntry:    "⛔", // :no_entry:
+	warning:    "❗", // :exclamation:
+	explosion:  "💥", // :boom:
+	hourglass:  "⌛", // :hourglass:
+	race:       "🏁", // :checkered_flag:
+	barChart:   "📊", // :bar_chart:
+	repeat:     "🔁", // :repeat:
+	plus:       "➕", // :heavy_plus_sign:
+	minus:      "➖", // :heavy_minus_sign:
+	dice:       "🎲", // :game_die:
+	snail:      "🐌", // :snail:
+	chartUp:    "📈", // :chart_with_upwards_trend:
+}
+
+// githubSummaryLimit is the maximum size of a GitHub Actions job summary
+// (1 MiB) and the default maximum size of a markdown report.
+const githubSummaryLimit = 1024 * 1024
+
+// reductions are applied (cumulatively, in order) to a markdown report that
+// exceeds its maximum size, until the report fits.  A reduction is noted in
+// the report only if it changed the report (i.e. something was omitted).
+var reductions = []struct {
+	description string
+	apply       func(*markdown)
+}{
+	{"output truncated to 20 lines", func(m *markdown) { m.truncate(20) }},
+	{"passed and skipped tests omitted", func(m *markdown) {
+		if m.mode == rmAllTests {
+			m.mode = rmFailedTests
+		}
+	}},
+	{"output truncated to 5 lines", func(m *markdown) { m.truncate(5) }},
+	{"output and stacks omitted", func(m *markdown) { m.omitOutput = true }},
+	{"packages collapsed", func(m *markdown) { m.collapsePackages = true }},
+	{"details omitted", func(m *markdown) { m.mode = rmSummaryOnly }},
 }
 
 // markdown is a markdown report writer.
 type markdown struct {
-	title string
-	mode  reportMode
+	title            string
+	mode             reportMode
+	thresholds       *thresholds          // pass rate thresholds for the report icon (nil: defaultThresholds)
+	links            *sourceLinks         // links for source references in test output (nil: not linked)
+	snippets         *snippets            // source code around source references in test output (nil: no snippets)
+	module           *module              // the module tested, identifying the frames of interest in a panic (nil: the package)
+	collapse         bool                 // true to write test output and packages in collapsible <details> elements
+	maxLines         int                  // the maximum number of lines of each block of output (0: no limit)
+	maxSize          int                  // the maximum size of the report, in bytes (0: githubSummaryLimit; < 0: no limit)
+	omitOutput       bool                 // true to omit test and package output and stacks (to reduce the size of the report)
+	collapsePackages bool                 // true to write a summary of each package in place of its tests (to reduce the size of the report)
+	reduced          []string             // descriptions of the reductions applied to fit the maximum size
+	changes          changes              // the changes in test results compared with a baseline (nil: no baseline)
+	regressions      []durationRegression // the tests and packages that took longer than in a baseline
+	trend            []historyEntry       // the most recent runs in a history, ending with the testrun (nil: no history)
+	slowest          int                  // the number of slowest tests and packages to report (0: none)
+	budget           time.Duration        // the duration budget of a test; tests exceeding the budget are reported (0: no budget)
 	*IndentWriter
 	*testrun
 }
 
 // getReportIcon returns the icon to use for the report based on the
-// testrun pass rate %age and number of failed and skipped tests.
+// testrun pass rate %age (relative to the report thresholds) and number of
+// failed and skipped tests.  A testrun with a package failure (or a package
+// that failed to build) is red, even if no tests failed; a testrun with
+// skipped or flaky tests (and no failed tests) is yellow.
 func (m markdown) getReportIcon() string {
-	if m.numFailed == 0 && m.numSkipped > 0 {
+	if m.numPackageFailed > 0 || m.numBuildFailed > 0 {
+		return icon.redBook
+	}
+	if m.numFailed == 0 && (m.numSkipped > 0 || m.numFlaky > 0) {
 		return icon.yellowBook
 	}
 
-	switch {
-	case m.percentPassed == 100:
-		return icon.greenBook
-	case m.percentPassed > 94:
-		return icon.yellowBook
-	case m.percentPassed > 84:
-		return icon.orangeBook
-	default:
-		return icon.redBook
+	th := defaultThresholds
+	if m.thresholds != nil {
+		th = *m.thresholds
 	}
+	return th.icon(m.percentPassed)
 }
 
 // export produces a markdown report to the specified writer.
+//
+// If the report exceeds the maximum size, reductions are applied until the
+// report fits, with a note in the report identifying what was omitted.  If
+// the report does not fit with all reductions applied, the fully reduced
+// report is written regardless.
 func (m *markdown) export(w io.Writer) error {
-	m.IndentWriter = &IndentWriter{output: w}
+	if m.maxSize < 0 {
+		m.IndentWriter = &IndentWriter{output: w}
+		return m.render()
+	}
+	limit := coalesce(m.maxSize, githubSummaryLimit)
+
+	for i := 0; ; i++ {
+		buf := &bytes.Buffer{}
+		m.IndentWriter = &IndentWriter{output: buf, limit: limit}
+		if i == len(reductions) {
+			m.limit = 0
+		}
+		err := m.render()
+		if errors.Is(err, ErrSizeLimitExceeded) {
+			if err := m.reduce(reductions[i].description, reductions[i].apply); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(buf.Bytes())
+		return err
+	}
+}
+
+// reduce applies a reduction to the report.  The reduction is noted in the
+// report only if the report rendered with the reduction applied differs
+// from the report without it (e.g. truncating output is not noted if no
+// output was longer than the maximum number of lines).
+func (m *markdown) reduce(description string, apply func(*markdown)) error {
+	before, err := m.rendered()
+	if err != nil {
+		return err
+	}
+	apply(m)
+	after, err := m.rendered()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(before, after) {
+		m.reduced = append(m.reduced, description)
+	}
+	return nil
+}
+
+// rendered returns the report rendered without any size limit.
+func (m *markdown) rendered() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	m.IndentWriter = &IndentWriter{output: buf}
+	err := m.render()
+	return buf.Bytes(), err
+}
+
+// truncate reduces the maximum number of lines of each block of output of
+// a report to n (if the maximum is not already less).
+func (m *markdown) truncate(n int) {
+	if m.maxLines == 0 || m.maxLines > n {
+		m.maxLines = n
+	}
+}
 
+// render writes the markdown report.
+func (m *markdown) render() error {
 	icon := m.getReportIcon()
 	m.WriteLn("## %s&nbsp;&nbsp;%s", icon, m.title)
 	m.WriteLn()
 
+	if m.changes != nil {
+		m.writeChanges()
+	}
+	if len(m.regressions) > 0 {
+		m.writeRegressions()
+	}
 	m.writeSummary()
-	if m.numFailed > 0 && (m.mode != rmSummaryOnly) {
+	if len(m.reduced) > 0 {
+		m.writeReduced()
+	}
+	if len(m.trend) > 1 {
+		m.writeTrend()
+	}
+	if (m.numFailed > 0 || m.numBuildFailed > 0 || m.numPackageFailed > 0) && (m.mode != rmSummaryOnly) {
 		m.writeDetail()
 	}
+	if m.numFlaky > 0 && (m.mode != rmSummaryOnly) {
+		m.writeFlaky()
+	}
+	if m.numRaces > 0 && (m.mode != rmSummaryOnly) {
+		m.writeRaces()
+	}
+	if m.numBenchmarks > 0 && (m.mode != rmSummaryOnly) {
+		m.writeBenchmarks()
+	}
+	if (m.slowest > 0 || m.budget > 0) && (m.mode != rmSummaryOnly) {
+		m.writeSlowest()
+	}
 
 	m.WriteLn()
 	m.WriteLn("<hr>")
@@ -74,6 +234,98 @@ func (m *markdown) export(w io.Writer) error {
 	return m.error
 }
 
+// writeChanges writes the changes in test results compared with a
+// baseline.  Each change is written with the number of tests and (unless
+// the mode is rmSummaryOnly) a collapsible list of the tests, initially
+// expanded for new failures.
+func (m markdown) writeChanges() {
+	m.WriteLn("### Changes since baseline")
+	m.WriteLn()
+	if m.changes.count() == 0 {
+		m.WriteLn("_no changes_")
+		m.WriteLn()
+		return
+	}
+
+	m.WriteXMLElement(func() {
+		for _, tc := range testChanges {
+			tests := m.changes[tc]
+			if len(tests) == 0 {
+				continue
+			}
+			label := tc.String()
+			if tc == tcNewFailure && len(tests) > 1 {
+				label += "s"
+			}
+			m.WriteXMLElement(func() {
+				m.WriteLn("<td>%s</td>", map[testChange]string{
+					tcNewFailure:   icon.redDot,
+					tcStillFailing: icon.repeat,
+					tcFixed:        icon.greenTick,
+					tcAdded:        icon.plus,
+					tcRemoved:      icon.minus,
+				}[tc])
+				if m.mode == rmSummaryOnly {
+					m.WriteLn("<td><b>%d %s</b></td>", len(tests), label)
+					return
+				}
+				m.WriteXMLElement(func() {
+					open := map[bool]string{true: " open", false: ""}[tc == tcNewFailure]
+					m.WriteLn("<details%s><summary><b>%d %s</b></summary>", open, len(tests), label)
+					for _, t := range tests {
+						m.WriteLn("<div><b>%s</b> <i>%s</i></div>", t.path, t.packageName)
+					}
+					m.WriteLn("</details>")
+				}, "td")
+			}, "tr", "valign='top'")
+		}
+	}, "table")
+	m.WriteLn()
+}
+
+// writeRegressions writes the tests and packages that took longer than in
+// a baseline, with the elapsed time in the baseline and the testrun and the
+// increase (as a %age of the baseline, if the baseline is not zero).
+func (m markdown) writeRegressions() {
+	m.WriteLn("### Performance regressions")
+	m.WriteLn()
+	m.WriteXMLElement(func() {
+		m.WriteXMLElement(func() {
+			m.WriteLn("<th></th>")
+			m.WriteLn("<th></th>")
+			m.WriteLn("<th>baseline</th>")
+			m.WriteLn("<th>elapsed</th>")
+			m.WriteLn("<th>change</th>")
+		}, "tr")
+		for _, r := range m.regressions {
+			name := "<b>" + r.packageName + "</b>"
+			if r.path != "" {
+				name = "<b>" + r.path + "</b><br>" + r.packageName
+			}
+			change := "+" + (r.elapsed - r.baseline).String()
+			if r.baseline > 0 {
+				change += fmt.Sprintf(" (+%d%%)", (r.elapsed-r.baseline)*100/r.baseline)
+			}
+			m.WriteXMLElement(func() {
+				m.WriteLn("<td>%s</td>", icon.chartUp) //NOSONAR
+				m.WriteLn("<td>%s</td>", name)
+				m.WriteLn("<td align='right'>%s</td>", r.baseline)
+				m.WriteLn("<td align='right'>%s</td>", r.elapsed)
+				m.WriteLn("<td align='right'>%s</td>", change)
+			}, "tr")
+		}
+	}, "table")
+	m.WriteLn()
+}
+
+// writeReduced writes a note identifying the reductions applied to the
+// report to fit the maximum size.
+func (m markdown) writeReduced() {
+	m.WriteLn()
+	m.WriteLn("> %s _report reduced to fit the maximum size (%d bytes): %s_", icon.warning, coalesce(m.maxSize, githubSummaryLimit), strings.Join(m.reduced, ", "))
+	m.WriteLn()
+}
+
 // writeSummary writes the summary section of the markdown report.
 func (m markdown) writeSummary() {
 	writeRow := func(i string, h string, v string) {
@@ -92,13 +344,31 @@ func (m markdown) writeSummary() {
 			m.WriteLn("<td><b>tests</b></td>")
 			m.WriteLn("<td align='right'>%d</td>", m.numTests) //NOSONAR
 		}, "tr")
+		if m.numBuildFailed > 0 {
+			writeRow(icon.noEntry, "build failed", fmt.Sprintf("%d", m.numBuildFailed))
+		}
+		if m.numPackageFailed > 0 {
+			writeRow(icon.redDot, "package failed", fmt.Sprintf("%d", m.numPackageFailed))
+		}
 		if m.numFailed > 0 {
 			writeRow(icon.redDot, "failed", fmt.Sprintf("%d", m.numFailed))
 		}
+		if m.numFlaky > 0 {
+			writeRow(icon.dice, "flaky", fmt.Sprintf("%d", m.numFlaky))
+		}
+		if m.numRaces > 0 {
+			writeRow(icon.race, "data races", fmt.Sprintf("%d", m.numRaces))
+		}
 		if m.numSkipped > 0 {
 			writeRow(icon.mutedBell, "skipped", fmt.Sprintf("%d", m.numSkipped))
 		}
+		if m.numConflicts > 0 {
+			writeRow(icon.warning, "conflicting results", fmt.Sprintf("%d", m.numConflicts))
+		}
 		writeRow(m.getReportIcon(), "passed", fmt.Sprintf("%d%%", m.percentPassed))
+		if m.coverage != nil {
+			writeRow(icon.barChart, "coverage", fmt.Sprintf("%.1f%%", *m.coverage))
+		}
 	}, "table")
 }
 
@@ -116,6 +386,10 @@ func (m markdown) writeDetail() {
 
 		m.WriteXMLElement(func() {
 			m.WriteLn("<td>%s</td>", icon) //NOSONAR
+			if m.hasCoverage() {
+				m.WriteLn("<td colspan=4><b>%d %s</b></td>", n, s)
+				return
+			}
 			m.WriteLn("<td colspan=3><b>%d %s</b></td>", n, s)
 		}, "tr")
 	}
@@ -135,43 +409,258 @@ func (m markdown) writeDetail() {
 // writePackage writes the test results for a package.  If the mode
 // is rmAllTests, then all tests are written (including passed and
 // skipped tests).  Otherwise, only failed tests are written.
+//
+// If the package failed to build, the build output is written in
+// place of any tests.
+//
+// If the report is collapsed, the tests of the package are written in a
+// table in a collapsible <details> element, summarising the results of the
+// package.  If packages are collapsed (to reduce the size of the report),
+// only the summary is written.
 func (m markdown) writePackage(p *packageinfo) {
 	if (m.mode == rmAllTests) || !p.passed {
-		icon := map[bool]string{
+		pkgicon := map[bool]string{
 			true:  icon.greenTick,
 			false: icon.redDot,
+		}[p.passed]
+		if p.buildFailed {
+			pkgicon = icon.noEntry
 		}
 
 		m.WriteXMLElement(func() {
-			m.WriteLn("<td>%s</td>", icon[p.passed]) //NOSONAR
+			m.WriteLn("<td>%s</td>", pkgicon) //NOSONAR
 			m.WriteLn("<td colspan='2'><b>%s</b></td>", p.name)
+			m.writeCoverage(p)
 			m.WriteLn("<td align='right'>%s</td>", p.elapsed)
 		}, "tr")
-		m.writeTests(p)
+		colspan := map[bool]string{true: "colspan=4", false: "colspan=3"}[m.hasCoverage()]
+		if m.collapsePackages {
+			m.WriteXMLElement(func() {
+				m.WriteLn("<td></td>")
+				m.WriteLn("<td %s><i>%s</i></td>", colspan, m.packageSummary(p))
+			}, "tr")
+			return
+		}
+		if !m.collapse {
+			m.writePackageDetail(p)
+			return
+		}
+		m.WriteXMLElement(func() {
+			m.WriteLn("<td></td>")
+			m.WriteXMLElement(func() {
+				m.WriteLn("<details><summary><i>%s</i></summary>", m.packageSummary(p))
+				m.WriteXMLElement(func() { m.writePackageDetail(p) }, "table")
+				m.WriteLn("</details>")
+			}, "td", colspan)
+		}, "tr")
+	}
+}
+
+// writePackageDetail writes the build output, package failure and tests
+// of a package.
+func (m markdown) writePackageDetail(p *packageinfo) {
+	if p.buildFailed {
+		m.writeBuildOutput(p)
+		return
+	}
+	if p.failed || p.panic != nil {
+		m.writePackageFailure(p)
+	}
+	m.writeTests(p)
+}
+
+// packageSummary returns a summary of the results of a package, e.g.
+// "1 failed, 2 passed".
+func (m markdown) packageSummary(p *packageinfo) string {
+	if p.buildFailed {
+		return "build failed"
 	}
+	n := &testnode{}
+	for _, t := range p.tests {
+		n.add(t.result)
+	}
+	s := n.summary()
+	if p.failed || p.panic != nil {
+		s = strings.TrimSuffix("package failure, "+s, ", ")
+	}
+	return coalesce(s, "no tests")
+}
+
+// hasCoverage returns true if coverage was reported for the testrun.
+func (m markdown) hasCoverage() bool {
+	return m.testrun != nil && m.coverage != nil
+}
+
+// writeCoverage writes a cell containing the coverage of a package, if
+// coverage was reported for the testrun (otherwise nothing is written).  If
+// no package is specified (or the package reported no coverage), an empty
+// cell is written.
+func (m markdown) writeCoverage(p *packageinfo) {
+	switch {
+	case !m.hasCoverage():
+		return
+	case p == nil || p.coverage == nil:
+		m.WriteLn("<td></td>")
+	default:
+		m.WriteLn("<td align='right'>%.1f%%</td>", *p.coverage)
+	}
+}
+
+// writeBuildOutput writes the output of a failed build for a package.
+func (m markdown) writeBuildOutput(p *packageinfo) {
+	m.WriteXMLElement(func() {
+		m.WriteLn("<td></td>")
+		m.WriteLn("<td>%s</td>", icon.noEntry) //NOSONAR
+		m.WriteXMLElement(func() {
+			m.WriteLn("<b>build failed</b>")
+			if len(p.buildOutput) > 0 {
+				m.writePre(p.buildOutput)
+			}
+		}, "td")
+		m.writeCoverage(nil)
+		m.WriteLn("<td></td>")
+	}, "tr", "valign='top'")
+}
+
+// writePackageFailure writes a synthetic "package failure" entry for a
+// package that failed without any failed test (e.g. due to an error in
+// TestMain or a panic in an init() function), with any output of the
+// package not associated with a test and any panic in the package.
+func (m markdown) writePackageFailure(p *packageinfo) {
+	m.WriteXMLElement(func() {
+		m.WriteLn("<td></td>")
+		m.WriteLn("<td>%s</td>", icon.redDot) //NOSONAR
+		m.WriteXMLElement(func() {
+			m.WriteLn("<b>package failure</b>")
+			if len(p.output) > 0 {
+				m.writePre(p.output)
+			}
+			if p.panic != nil {
+				m.writePanic(p.name, p.panic)
+			}
+		}, "td")
+		m.writeCoverage(nil)
+		m.WriteLn("<td></td>")
+	}, "tr", "valign='top'")
 }
 
 // writeTests writes the test results for a package.  If the mode
 // is rmAllTests, then all tests are written (including passed and
 // skipped tests).  Otherwise, only failed tests are written.
+//
+// Each top-level test is written in a separate row.  A test with subtests
+// is written as a collapsible group (see writeTestGroup), with the result
+// of the test rolled up from its subtests.
 func (m markdown) writeTests(p *packageinfo) {
-	icon := map[testResult]string{
+	for _, n := range testTree(p.tests) {
+		if n.result() != trFailed && m.mode != rmAllTests {
+			continue
+		}
+		m.WriteXMLElement(func() {
+			m.WriteLn("<td></td>")
+			m.WriteLn("<td>%s</td>", m.nodeIcon(n)) //NOSONAR
+			m.WriteXMLElement(func() {
+				if len(n.children) > 0 {
+					m.writeTestGroup(n, "")
+					return
+				}
+				m.WriteLn("<b>%s</b>", n.path)
+				m.writeTest(n.test)
+			}, "td")
+			m.writeCoverage(nil)
+			if n.test != nil {
+				m.WriteLn("<td align='right'>%s</td>", n.test.elapsed)
+			} else {
+				m.WriteLn("<td></td>")
+			}
+		}, "tr", "valign='top'")
+	}
+}
+
+// testIcon returns the icon for a test result.
+func (m markdown) testIcon(r testResult) string {
+	return map[testResult]string{
 		trPassed:  icon.greenTick,
 		trFailed:  icon.redDot,
 		trSkipped: icon.mutedBell,
+	}[r]
+}
+
+// nodeIcon returns the icon for a node in a tree of tests: the icon for the
+// (rolled up) result of the node or, for a flaky test, a distinct icon.
+func (m markdown) nodeIcon(n *testnode) string {
+	if n.test != nil && n.test.flaky() {
+		return icon.dice
 	}
-	for _, t := range p.tests {
-		if t.result == trFailed || (m.mode == rmAllTests) {
-			m.WriteXMLElement(func() {
-				m.WriteLn("<td></td>")
-				m.WriteLn("<td>%s</td>", icon[t.result]) //NOSONAR
-				m.WriteXMLElement(func() {
-					m.WriteLn("<b>%s</b>", t.path)
-					m.writeOutput(t.output)
-				}, "td")
-				m.WriteLn("<td align='right'>%s</td>", t.elapsed)
-			}, "tr", "valign='top'")
+	return m.testIcon(n.result())
+}
+
+// writeTestGroup writes a test with subtests as a collapsible <details>
+// group, summarising the results of the subtests.  The group is initially
+// expanded if the test failed.  The output of the test (if any) is written
+// followed by each subtest; a subtest with subtests of its own is written
+// as a nested group.
+//
+// If the mode is not rmAllTests, only failed subtests are written.
+//
+// The icon is written in the summary of the group, preceding the name of
+// the test; no icon is written for a top-level test (the icon is written
+// in a separate column).
+func (m markdown) writeTestGroup(n *testnode, testicon string) {
+	open := map[bool]string{true: " open", false: ""}[n.result() == trFailed]
+	name := n.path
+	if testicon != "" {
+		name = n.name
+		testicon += " "
+	}
+	m.WriteLn("<details%s><summary>%s<b>%s</b> <i>%s</i></summary>", open, testicon, name, n.summary())
+	m.writeTest(n.test)
+	for _, c := range n.children {
+		if c.result() != trFailed && m.mode != rmAllTests {
+			continue
+		}
+		if len(c.children) > 0 {
+			m.writeTestGroup(c, m.nodeIcon(c))
+			continue
+		}
+		elapsed := ""
+		if c.test != nil {
+			elapsed = " <i>" + c.test.elapsed.String() + "</i>"
+		}
+		m.WriteLn("<div>%s <b>%s</b>%s</div>", m.nodeIcon(c), c.name, elapsed)
+		m.writeTest(c.test)
+	}
+	m.WriteLn("</details>")
+}
+
+// writeTest writes any conflict, output and panic of a test.  If the report
+// is collapsed, the output is written in a collapsible <details> element.
+func (m markdown) writeTest(t *testinfo) {
+	if t == nil {
+		return
+	}
+	if t.conflict {
+		m.WriteLn("%s <i>conflicting results in merged reports</i><br>", icon.warning)
+	}
+	if m.omitOutput {
+		if t.panic != nil {
+			m.writePanic(t.packageName, t.panic)
 		}
+		return
+	}
+	if m.collapse && len(t.output) > 0 {
+		n := 0
+		for _, log := range t.output {
+			n += len(log)
+		}
+		m.WriteLn("<details><summary><i>output (%d %s)</i></summary>", n, map[bool]string{true: "line", false: "lines"}[n == 1])
+		m.writeOutput(t)
+		m.WriteLn("</details>")
+	} else {
+		m.writeOutput(t)
+	}
+	if t.panic != nil {
+		m.writePanic(t.packageName, t.panic)
 	}
 }
 
@@ -193,22 +682,357 @@ func (m markdown) writeTests(p *packageinfo) {
 // NOTE: The rendered output has space characters replaced with
 // "&nbsp;" to prevent the markdown renderer from wrapping lines
 // of output.
-func (m markdown) writeOutput(output map[string][]string) {
+func (m markdown) writeOutput(t *testinfo) {
 	keys := []string{}
-	for k := range output {
+	for k := range t.output {
 		keys = append(keys, k)
 	}
 	slices.Sort(keys)
 
 	for _, ref := range keys {
-		log := output[ref]
-		m.WriteLn("<div><i>%s</i></div>", ref)
-		m.Write("<pre>%s", strings.Replace(log[0], " ", "&nbsp;", -1))
-		m.WriteIndented(func() {
-			for _, s := range log[1:] {
-				m.Write("\n%s", strings.Replace(s, " ", "&nbsp;", -1))
+		log := t.output[ref]
+		if url := m.links.link(t.packageName, ref); url != "" {
+			m.WriteLn("<div><i><a href='%s'>%s</a></i></div>", url, ref)
+		} else {
+			m.WriteLn("<div><i>%s</i></div>", ref)
+		}
+		if snippet := m.snippets.snippet(t.packageName, ref); snippet != nil {
+			m.writeSnippet(snippet)
+		}
+		m.writePre(log)
+	}
+}
+
+// writePre writes lines of output in a <pre> element.  If there are more
+// lines than the maximum for the report, only the maximum number of lines
+// is written, followed by a note of the number of lines omitted.
+func (m markdown) writePre(lines []string) {
+	if m.omitOutput {
+		return
+	}
+	more := 0
+	if m.maxLines > 0 && len(lines) > m.maxLines {
+		lines, more = lines[:m.maxLines], len(lines)-m.maxLines
+	}
+	m.Write("<pre>%s", strings.Replace(lines[0], " ", "&nbsp;", -1))
+	m.WriteIndented(func() {
+		for _, s := range lines[1:] {
+			m.Write("\n%s", strings.Replace(s, " ", "&nbsp;", -1))
+		}
+		m.WriteLn("</pre>")
+	})
+	if more > 0 {
+		m.WriteLn("<div><i>%d more %s</i></div>", more, map[bool]string{true: "line", false: "lines"}[more == 1])
+	}
+}
+
+// writeSnippet writes a snippet of source code in a <pre> element, with
+// each line preceded by the line number.  The line identified by the
+// source reference is marked (>) and bold.
+func (m markdown) writeSnippet(snippet []snippetLine) {
+	width := len(strconv.Itoa(snippet[len(snippet)-1].number))
+	lines := make([]string, 0, len(snippet))
+	for _, l := range snippet {
+		marker := " "
+		if l.ref {
+			marker = ">"
+		}
+		s := fmt.Sprintf("%s %*d  %s", marker, width, l.number, l.text)
+		s = strings.Replace(html.EscapeString(s), " ", "&nbsp;", -1)
+		if l.ref {
+			s = "<b>" + s + "</b>"
+		}
+		lines = append(lines, s)
+	}
+	m.Write("<pre>%s</pre>", strings.Join(lines, "\n"))
+	m.WriteLn()
+}
+
+// writePanic writes a collapsible section for a panic (or test timeout) in
+// a package.  The summary of the section identifies the panic; the section
+// contains the stack of the goroutine that panicked, with each function
+// followed by the source location of the call, indented:
+//
+//	<details><summary>💥 <b>panic:</b> message</summary>
+//	<pre>runtime.panic
+//	&nbsp;&nbsp;&nbsp;&nbsp;/usr/local/go/src/runtime/panic.go:1
+//	<b>github.com/foo/package.TestFoo
+//	&nbsp;&nbsp;&nbsp;&nbsp;/src/foo/foo_test.go:12</b></pre>
+//	</details>
+//
+// The first frame in the module tested (or the package, if the module is
+// not known) is bold and preceded by a snippet of the source at that
+// location (if snippets are enabled).
+func (m markdown) writePanic(pkg string, pi *panicinfo) {
+	summary := fmt.Sprintf("%s <b>panic:</b> %s", icon.explosion, html.EscapeString(pi.message))
+	if pi.timeout {
+		summary = fmt.Sprintf("%s <b>%s</b>", icon.hourglass, html.EscapeString(pi.message))
+	}
+
+	if m.omitOutput {
+		m.WriteLn("<div>%s</div>", summary)
+		return
+	}
+	m.WriteLn("<details><summary>%s</summary>", summary)
+	m.writeStack(pkg, pi.stack, true)
+	m.WriteLn("</details>")
+}
+
+// writeStack writes the frames of a stack in a <pre> element, with each
+// function followed by the source location of the call, indented.  The
+// first frame in the module tested (or the package, if the module is not
+// known) is bold and, if required, preceded by a snippet of the source at
+// that location (if snippets are enabled).
+func (m markdown) writeStack(pkg string, stack []stackframe, snippet bool) {
+	focus := focusFrame(stack, m.module, pkg)
+	if focus != -1 && snippet {
+		f := stack[focus]
+		if snippet := m.snippets.snippet(f.packageName(), f.ref()); snippet != nil {
+			m.writeSnippet(snippet)
+		}
+	}
+	lines := make([]string, 0, len(stack))
+	for i, f := range stack {
+		s := html.EscapeString(f.function) + "\n" + strings.Repeat("&nbsp;", 4) + html.EscapeString(fmt.Sprintf("%s:%d", f.file, f.line))
+		if i == focus {
+			s = "<b>" + s + "</b>"
+		}
+		lines = append(lines, s)
+	}
+	m.Write("<pre>%s</pre>", strings.Join(lines, "\n"))
+	m.WriteLn()
+}
+
+// writeFlaky writes a section identifying each flaky test, with the result
+// of each attempt and the number of attempts that failed.
+func (m markdown) writeFlaky() {
+	m.WriteLn()
+	m.WriteLn("### Flaky Tests")
+	m.WriteLn()
+	m.WriteXMLElement(func() {
+		for _, p := range m.packages {
+			for _, t := range p.tests {
+				if !t.flaky() {
+					continue
+				}
+				results := make([]string, 0, len(t.attempts))
+				failed := 0
+				for _, r := range t.attempts {
+					results = append(results, m.testIcon(r))
+					if r == trFailed {
+						failed++
+					}
+				}
+				m.WriteXMLElement(func() {
+					m.WriteLn("<td>%s</td>", icon.dice) //NOSONAR
+					m.WriteLn("<td><b>%s</b><br>%s</td>", t.path, p.name)
+					m.WriteLn("<td>%s</td>", strings.Join(results, ""))
+					m.WriteLn("<td align='right'>%d of %d failed</td>", failed, len(t.attempts))
+				}, "tr")
 			}
-			m.WriteLn("</pre>")
-		})
+		}
+	}, "table")
+}
+
+// writeTrend writes a section presenting the pass rate, number of tests
+// and duration of the most recent runs in a history as Mermaid charts.
+// Each run is labelled with its (short) commit or, if the commit is not
+// known, the time of the run.
+func (m markdown) writeTrend() {
+	labels := make([]string, 0, len(m.trend))
+	passRate := make([]string, 0, len(m.trend))
+	tests := make([]string, 0, len(m.trend))
+	duration := make([]string, 0, len(m.trend))
+	for _, e := range m.trend {
+		label := e.Time.Format("Jan 2 15:04")
+		if e.Commit != "" {
+			label = e.Commit[:min(7, len(e.Commit))]
+		}
+		labels = append(labels, strconv.Quote(label))
+		passRate = append(passRate, strconv.Itoa(e.PercentPassed))
+		tests = append(tests, strconv.Itoa(e.Tests))
+		duration = append(duration, strconv.FormatFloat(e.Elapsed, 'f', 1, 64))
+	}
+
+	chart := func(title, axis, kind string, values []string) {
+		m.WriteLn()
+		m.WriteLn("```mermaid")
+		m.WriteLn("xychart-beta")
+		m.WriteLn("  title %q", title)
+		m.WriteLn("  x-axis [%s]", strings.Join(labels, ", "))
+		m.WriteLn("  y-axis %s", axis)
+		m.WriteLn("  %s [%s]", kind, strings.Join(values, ", "))
+		m.WriteLn("```")
+	}
+
+	m.WriteLn()
+	m.WriteLn("### Trend (last %d runs)", len(m.trend))
+	chart("pass rate", "\"%\" 0 --> 100", "line", passRate)
+	chart("tests", "\"tests\"", "bar", tests)
+	chart("duration", "\"seconds\"", "line", duration)
+}
+
+// writeRaces writes a section identifying each data race detected, grouped
+// by package, with races in a package that could not be attributed to a
+// test preceding races in tests.
+func (m markdown) writeRaces() {
+	m.WriteLn()
+	m.WriteLn("### Data Races")
+	m.WriteLn()
+	m.WriteXMLElement(func() {
+		for _, p := range m.packages {
+			for _, r := range p.races {
+				m.writeRace(p.name, "", r)
+			}
+			for _, t := range p.tests {
+				for _, r := range t.races {
+					m.writeRace(p.name, t.path, r)
+				}
+			}
+		}
+	}, "table")
+}
+
+// writeRace writes a data race detected in a package (and test, if
+// known), presenting the stack of each of the conflicting accesses
+// followed by a collapsible section containing the sites at which the
+// goroutines involved were created.
+func (m markdown) writeRace(pkg string, test string, r *raceinfo) {
+	m.WriteXMLElement(func() {
+		m.WriteLn("<td>%s</td>", icon.race) //NOSONAR
+		if test == "" {
+			m.WriteLn("<td><b>%s</b></td>", pkg)
+		} else {
+			m.WriteLn("<td><b>%s</b><br>%s</td>", test, pkg)
+		}
+	}, "tr")
+	m.WriteXMLElement(func() {
+		m.WriteLn("<td></td>")
+		m.WriteXMLElement(func() {
+			for _, a := range r.accesses {
+				m.WriteLn("<b>%s</b>", html.EscapeString(a.description))
+				m.writeStack(pkg, a.stack, false)
+			}
+			if len(r.goroutines) > 0 {
+				m.WriteLn("<details><summary>goroutines</summary>")
+				for _, g := range r.goroutines {
+					m.WriteLn("<b>%s</b>", html.EscapeString(g.description))
+					m.writeStack(pkg, g.stack, false)
+				}
+				m.WriteLn("</details>")
+			}
+		}, "td")
+	}, "tr", "valign='top'")
+}
+
+// writeBenchmarks writes a table of the benchmark results for each package
+// with benchmarks.  Columns for B/op and allocs/op are always present;
+// any other metrics reported by a benchmark (e.g. MB/s or custom metrics)
+// are written together in a final column.
+func (m markdown) writeBenchmarks() {
+	number := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	optional := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return number(*v)
+	}
+
+	m.WriteLn()
+	m.WriteLn("### Benchmarks")
+	m.WriteLn()
+	m.WriteXMLElement(func() {
+		m.WriteXMLElement(func() {
+			m.WriteLn("<th></th>")
+			m.WriteLn("<th>benchmark</th>")
+			m.WriteLn("<th>iterations</th>")
+			m.WriteLn("<th>ns/op</th>")
+			m.WriteLn("<th>B/op</th>")
+			m.WriteLn("<th>allocs/op</th>")
+			m.WriteLn("<th>metrics</th>")
+		}, "tr")
+		for _, p := range m.packages {
+			if len(p.benchmarks) == 0 {
+				continue
+			}
+			m.WriteXMLElement(func() {
+				m.WriteLn("<td colspan=7><b>%s</b></td>", p.name)
+			}, "tr")
+			for _, b := range p.benchmarks {
+				bicon := map[bool]string{
+					true:  icon.redDot,
+					false: icon.greenTick,
+				}[b.failed]
+
+				units := []string{}
+				for unit := range b.metrics {
+					units = append(units, unit)
+				}
+				slices.Sort(units)
+				metrics := []string{}
+				for _, unit := range units {
+					metrics = append(metrics, number(b.metrics[unit])+" "+unit)
+				}
+
+				m.WriteXMLElement(func() {
+					m.WriteLn("<td>%s</td>", bicon) //NOSONAR
+					m.WriteLn("<td>%s</td>", b.name)
+					m.WriteLn("<td align='right'>%d</td>", b.iterations)
+					m.WriteLn("<td align='right'>%s</td>", number(b.nsPerOp))
+					m.WriteLn("<td align='right'>%s</td>", optional(b.bytesPerOp))
+					m.WriteLn("<td align='right'>%s</td>", optional(b.allocsPerOp))
+					m.WriteLn("<td>%s</td>", strings.Join(metrics, "<br>"))
+				}, "tr")
+			}
+		}
+	}, "table")
+}
+
+// writeSlowest writes a section identifying the slowest tests (and any
+// further tests exceeding the duration budget, flagged with an icon) and,
+// if a number of slowest tests is specified, the slowest packages.
+func (m markdown) writeSlowest() {
+	tests := m.slowestTests(m.slowest, m.budget)
+	over := 0
+	for _, t := range tests {
+		if m.budget > 0 && t.elapsed > m.budget {
+			over++
+		}
 	}
+
+	m.WriteLn()
+	m.WriteLn("### Slowest Tests")
+	if m.budget > 0 {
+		m.WriteLn()
+		m.WriteLn("> %s _%d of %d tests exceeded the duration budget (%s)_", icon.snail, over, m.numTests, m.budget)
+	}
+	if len(tests) > 0 {
+		m.WriteLn()
+		m.WriteXMLElement(func() {
+			for _, t := range tests {
+				m.WriteXMLElement(func() {
+					m.WriteLn("<td>%s</td>", map[bool]string{true: icon.snail, false: ""}[m.budget > 0 && t.elapsed > m.budget])
+					m.WriteLn("<td><b>%s</b><br>%s</td>", t.path, t.packageName)
+					m.WriteLn("<td align='right'>%s</td>", t.elapsed)
+				}, "tr")
+			}
+		}, "table")
+	}
+
+	if m.slowest == 0 {
+		return
+	}
+	m.WriteLn()
+	m.WriteLn("### Slowest Packages")
+	m.WriteLn()
+	m.WriteXMLElement(func() {
+		for _, p := range m.slowestPackages(m.slowest) {
+			m.WriteXMLElement(func() {
+				m.WriteLn("<td><b>%s</b></td>", p.name)
+				m.WriteLn("<td align='right'>%s</td>", p.elapsed)
+			}, "tr")
+		}
+	}, "table")
 }
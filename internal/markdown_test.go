@@ -3,6 +3,8 @@ package internal
 import (
 	"bytes"
 	"fmt"
+	"io/fs"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,6 +29,53 @@ func TestGetReportIcon(t *testing.T) {
 		test.That(t, result).Equals(icon.yellowBook)
 	})
 
+	t.Run("flaky tests, none failed", func(t *testing.T) {
+		// ARRANGE
+		md := &markdown{testrun: &testrun{numTests: 1, numPassed: 1, numFlaky: 1, percentPassed: 100}}
+
+		// ACT
+		result := md.getReportIcon()
+
+		// ASSERT
+		test.That(t, result).Equals(icon.yellowBook)
+	})
+
+	t.Run("package failed, no tests failed", func(t *testing.T) {
+		// ARRANGE
+		md := &markdown{testrun: &testrun{numTests: 1, numPassed: 1, numPackageFailed: 1, percentPassed: 100}}
+
+		// ACT
+		result := md.getReportIcon()
+
+		// ASSERT
+		test.That(t, result).Equals(icon.redBook)
+	})
+
+	t.Run("build failed, no tests failed", func(t *testing.T) {
+		// ARRANGE
+		md := &markdown{testrun: &testrun{numTests: 1, numPassed: 1, numBuildFailed: 1, percentPassed: 100}}
+
+		// ACT
+		result := md.getReportIcon()
+
+		// ASSERT
+		test.That(t, result).Equals(icon.redBook)
+	})
+
+	t.Run("configured thresholds", func(t *testing.T) {
+		// ARRANGE
+		md := &markdown{
+			thresholds: &thresholds{orange: 50, yellow: 75},
+			testrun:    &testrun{numFailed: 1, percentPassed: 60},
+		}
+
+		// ACT
+		result := md.getReportIcon()
+
+		// ASSERT
+		test.That(t, result).Equals(icon.orangeBook)
+	})
+
 	testcases := []struct {
 		from   int
 		to     int
@@ -208,6 +257,109 @@ func TestMarkdown(t *testing.T) {
 				})
 			},
 		},
+		{scenario: "summary/1 package, build failed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmSummaryOnly,
+					testrun:      &testrun{},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				md.testrun.packages = []*packageinfo{{}}
+				md.testrun.numBuildFailed = 1
+
+				// ACT
+				md.writeSummary()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<table>",
+					"  <tr>",
+					"    <td><b>packages</b></td>",
+					"    <td>1</td>",
+					"    <td>0s</td>",
+					"    <td><b>tests</b></td>",
+					"    <td align='right'>0</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td colspan=3 align='right'>⛔</td>",
+					"    <td>build failed</td>",
+					"    <td align='right'>1</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td colspan=3 align='right'>📕</td>",
+					"    <td>passed</td>",
+					"    <td align='right'>0%</td>",
+					"  </tr>",
+					"</table>",
+					"",
+				})
+			},
+		},
+
+		{scenario: "summary/conflicting results",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmSummaryOnly,
+					testrun:      &testrun{},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				md.testrun.packages = []*packageinfo{{}}
+				md.testrun.numTests = 1
+				md.testrun.numPassed = 1
+				md.testrun.numConflicts = 1
+				md.testrun.percentPassed = 100
+
+				// ACT
+				md.writeSummary()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					"  <tr>",
+					"    <td colspan=3 align='right'>❗</td>",
+					"    <td>conflicting results</td>",
+					"    <td align='right'>1</td>",
+					"  </tr>",
+				})
+			},
+		},
+
+		{scenario: "summary/package failed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmSummaryOnly,
+					testrun:      &testrun{},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				md.testrun.packages = []*packageinfo{{}}
+				md.testrun.numTests = 1
+				md.testrun.numPassed = 1
+				md.testrun.numPackageFailed = 1
+				md.testrun.percentPassed = 100
+
+				// ACT
+				md.writeSummary()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					"  <tr>",
+					"    <td colspan=3 align='right'>🔴</td>",
+					"    <td>package failed</td>",
+					"    <td align='right'>1</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td colspan=3 align='right'>📕</td>",
+					"    <td>passed</td>",
+					"    <td align='right'>100%</td>",
+					"  </tr>",
+				})
+			},
+		},
 
 		// output tests
 		{scenario: "output/1 source, 1 line of output",
@@ -222,7 +374,7 @@ func TestMarkdown(t *testing.T) {
 				}
 
 				// ACT
-				md.writeOutput(output)
+				md.writeOutput(&testinfo{output: output})
 
 				// ASSERT
 				test.Strings(t, buf.Bytes()).Equals([]string{
@@ -232,6 +384,61 @@ func TestMarkdown(t *testing.T) {
 				})
 			},
 		},
+		{scenario: "output/1 source, linked",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osStat, func(string) (fs.FileInfo, error) { return nil, nil })()
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					links: &sourceLinks{
+						url:    "https://github.com/foo/repo/blob/abc123/{path}#L{line}",
+						module: &module{path: "github.com/foo/repo"},
+					},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				ti := &testinfo{
+					packageName: "github.com/foo/repo/package",
+					output: map[string][]string{
+						"filename_test.go:12": {"output"},
+					},
+				}
+
+				// ACT
+				md.writeOutput(ti)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<div><i><a href='https://github.com/foo/repo/blob/abc123/package/filename_test.go#L12'>filename_test.go:12</a></i></div>",
+					"<pre>output</pre>",
+					"",
+				})
+			},
+		},
+		{scenario: "output/snippet",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				snippet := []snippetLine{
+					{number: 9, text: "if got != want {"},
+					{number: 10, text: "    t.Errorf(\"got <%v>\", got)", ref: true},
+					{number: 11, text: "}"},
+				}
+
+				// ACT
+				md.writeSnippet(snippet)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<pre>&nbsp;&nbsp;&nbsp;9&nbsp;&nbsp;if&nbsp;got&nbsp;!=&nbsp;want&nbsp;{",
+					"<b>&gt;&nbsp;10&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;t.Errorf(&#34;got&nbsp;&lt;%v&gt;&#34;,&nbsp;got)</b>",
+					"&nbsp;&nbsp;11&nbsp;&nbsp;}</pre>",
+					"",
+				})
+			},
+		},
 		{scenario: "output/1 source, 2 lines of output",
 			exec: func(t *testing.T) {
 				// ARRANGE
@@ -247,7 +454,7 @@ func TestMarkdown(t *testing.T) {
 				}
 
 				// ACT
-				md.writeOutput(output)
+				md.writeOutput(&testinfo{output: output})
 
 				// ASSERT
 				test.Strings(t, buf.Bytes()).Equals([]string{
@@ -277,7 +484,7 @@ func TestMarkdown(t *testing.T) {
 				}
 
 				// ACT
-				md.writeOutput(output)
+				md.writeOutput(&testinfo{output: output})
 
 				// ASSERT
 				test.Strings(t, buf.Bytes()).Equals([]string{
@@ -291,6 +498,69 @@ func TestMarkdown(t *testing.T) {
 				})
 			},
 		},
+		{scenario: "output/max lines",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					maxLines:     2,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				output := map[string][]string{
+					"filename_test.go:12": {"line 1", "line 2", "line 3", "line 4"},
+					"filename_test.go:14": {"line 1", "line 2", "line 3"},
+					"filename_test.go:16": {"line 1", "line 2"},
+				}
+
+				// ACT
+				md.writeOutput(&testinfo{output: output})
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<div><i>filename_test.go:12</i></div>",
+					"<pre>line&nbsp;1",
+					"line&nbsp;2</pre>",
+					"<div><i>2 more lines</i></div>",
+					"<div><i>filename_test.go:14</i></div>",
+					"<pre>line&nbsp;1",
+					"line&nbsp;2</pre>",
+					"<div><i>1 more line</i></div>",
+					"<div><i>filename_test.go:16</i></div>",
+					"<pre>line&nbsp;1",
+					"line&nbsp;2</pre>",
+					"",
+				})
+			},
+		},
+		{scenario: "output/collapsed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					collapse:     true,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				output := map[string][]string{
+					"filename_test.go:12": {"line 1", "line 2"},
+					"filename_test.go:14": {"line 1"},
+				}
+
+				// ACT
+				md.writeTest(&testinfo{output: output})
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<details><summary><i>output (3 lines)</i></summary>",
+					"<div><i>filename_test.go:12</i></div>",
+					"<pre>line&nbsp;1",
+					"line&nbsp;2</pre>",
+					"<div><i>filename_test.go:14</i></div>",
+					"<pre>line&nbsp;1</pre>",
+					"</details>",
+					"",
+				})
+			},
+		},
 
 		// package tests
 		{scenario: "tests/failed tests mode, 3 tests, 1 failed, 1 skipped, 1 passed",
@@ -333,82 +603,999 @@ func TestMarkdown(t *testing.T) {
 				})
 			},
 		},
-
-		// detail tests
-		{scenario: "detail/1 package, 1 failed test, 1 passed (failed tests mode)",
+		{scenario: "tests/subtests/failed tests mode",
 			exec: func(t *testing.T) {
 				// ARRANGE
 				buf := bytes.NewBuffer(nil)
 				md := &markdown{
 					mode:         rmFailedTests,
-					testrun:      &testrun{},
 					IndentWriter: &IndentWriter{output: buf},
 				}
-				md.testrun.packages = []*packageinfo{{}}
-				md.testrun.numTests = 2
-				md.testrun.numFailed = 1
-				md.testrun.numPassed = 1
-				md.testrun.percentPassed = 50
-				md.testrun.packages = []*packageinfo{{
+				pkg := &packageinfo{
 					name:    "github.com/foo/package",
-					elapsed: 3 * time.Millisecond,
+					elapsed: 6 * time.Millisecond,
 					tests: []*testinfo{
-						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond},
-						{path: "Test2", result: trPassed, elapsed: 2 * time.Millisecond},
+						{path: "Test1", result: trFailed, elapsed: 3 * time.Millisecond},
+						{path: "Test1/a", result: trPassed, elapsed: 1 * time.Millisecond},
+						{path: "Test1/b", result: trFailed, elapsed: 2 * time.Millisecond},
+						{path: "Test2", result: trPassed, elapsed: 3 * time.Millisecond},
+						{path: "Test2/a", result: trPassed, elapsed: 3 * time.Millisecond},
 					},
-				}}
+				}
 
 				// ACT
-				md.writeDetail()
+				md.writePackage(pkg)
 
 				// ASSERT
 				test.Strings(t, buf.Bytes()).Equals([]string{
-					"<table>",
-					"  <tr>",
-					"    <td>🔴</td>",
-					"    <td colspan='2'><b>github.com/foo/package</b></td>",
-					"    <td align='right'>3ms</td>",
-					"  </tr>",
-					"  <tr valign='top'>",
-					"    <td></td>",
-					"    <td>🔴</td>",
-					"    <td>",
-					"      <b>Test1</b>",
-					"    </td>",
-					"    <td align='right'>1ms</td>",
-					"  </tr>",
-					"  <tr>",
-					"    <td>✅</td>",
-					"    <td colspan=3><b>1 test passed</b></td>",
-					"  </tr>",
-					"</table>",
+					"<tr>",
+					"  <td>🔴</td>",
+					"  <td colspan='2'><b>github.com/foo/package</b></td>",
+					"  <td align='right'>6ms</td>",
+					"</tr>",
+					"<tr valign='top'>",
+					"  <td></td>",
+					"  <td>🔴</td>",
+					"  <td>",
+					"    <details open><summary><b>Test1</b> <i>1 failed, 1 passed</i></summary>",
+					"    <div>🔴 <b>b</b> <i>2ms</i></div>",
+					"    </details>",
+					"  </td>",
+					"  <td align='right'>3ms</td>",
+					"</tr>",
 					"",
 				})
 			},
 		},
-		{scenario: "detail/1 package, 1 failed test, 2 passed (failed tests mode)",
+		{scenario: "tests/subtests/all tests mode",
 			exec: func(t *testing.T) {
 				// ARRANGE
 				buf := bytes.NewBuffer(nil)
 				md := &markdown{
-					mode:         rmFailedTests,
-					testrun:      &testrun{},
+					mode:         rmAllTests,
 					IndentWriter: &IndentWriter{output: buf},
 				}
-				md.testrun.packages = []*packageinfo{{}}
-				md.testrun.numTests = 3
-				md.testrun.numFailed = 1
-				md.testrun.numPassed = 2
-				md.testrun.percentPassed = 66
-				md.testrun.packages = []*packageinfo{{
+				pkg := &packageinfo{
 					name:    "github.com/foo/package",
 					elapsed: 6 * time.Millisecond,
+					passed:  true,
 					tests: []*testinfo{
-						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond},
-						{path: "Test2", result: trPassed, elapsed: 2 * time.Millisecond},
-						{path: "Test3", result: trPassed, elapsed: 3 * time.Millisecond},
+						{path: "Test1/a", result: trPassed, elapsed: 1 * time.Millisecond},
+						{path: "Test1/a/x", result: trPassed, elapsed: 1 * time.Millisecond},
+						{path: "Test1/b", result: trSkipped},
 					},
-				}}
+				}
+
+				// ACT
+				md.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<tr>",
+					"  <td>✅</td>",
+					"  <td colspan='2'><b>github.com/foo/package</b></td>",
+					"  <td align='right'>6ms</td>",
+					"</tr>",
+					"<tr valign='top'>",
+					"  <td></td>",
+					"  <td>✅</td>",
+					"  <td>",
+					"    <details><summary><b>Test1</b> <i>2 passed, 1 skipped</i></summary>",
+					"    <details><summary>✅ <b>a</b> <i>1 passed</i></summary>",
+					"    <div>✅ <b>x</b> <i>1ms</i></div>",
+					"    </details>",
+					"    <div>🔕 <b>b</b> <i>0s</i></div>",
+					"    </details>",
+					"  </td>",
+					"  <td></td>",
+					"</tr>",
+					"",
+				})
+			},
+		},
+		{scenario: "tests/build failed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:        "github.com/foo/package",
+					buildFailed: true,
+					buildOutput: []string{
+						"package/foo_test.go:6:6: declared and not used: n",
+						"package/foo_test.go:7:8: undefined: bar",
+					},
+				}
+
+				// ACT
+				md.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<tr>",
+					"  <td>⛔</td>",
+					"  <td colspan='2'><b>github.com/foo/package</b></td>",
+					"  <td align='right'>0s</td>",
+					"</tr>",
+					"<tr valign='top'>",
+					"  <td></td>",
+					"  <td>⛔</td>",
+					"  <td>",
+					"    <b>build failed</b>",
+					"    <pre>package/foo_test.go:6:6:&nbsp;declared&nbsp;and&nbsp;not&nbsp;used:&nbsp;n",
+					"package/foo_test.go:7:8:&nbsp;undefined:&nbsp;bar</pre>",
+					"  </td>",
+					"  <td></td>",
+					"</tr>",
+					"",
+				})
+			},
+		},
+
+		{scenario: "tests/conflicting results",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:    "github.com/foo/package",
+					elapsed: 1 * time.Millisecond,
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond, conflict: true},
+					},
+				}
+
+				// ACT
+				md.writeTests(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<tr valign='top'>",
+					"  <td></td>",
+					"  <td>🔴</td>",
+					"  <td>",
+					"    <b>Test1</b>",
+					"    ❗ <i>conflicting results in merged reports</i><br>",
+					"  </td>",
+					"  <td align='right'>1ms</td>",
+					"</tr>",
+					"",
+				})
+			},
+		},
+
+		{scenario: "tests/panic",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pi := &panicinfo{
+					message: "assignment to entry in nil map",
+					stack: []stackframe{
+						{function: "runtime.mapassign_faststr", file: "/go/src/runtime/map.go", line: 1},
+						{function: "github.com/foo/package.TestFoo", file: "/src/package/foo_test.go", line: 12},
+						{function: "testing.tRunner", file: "/go/src/testing/testing.go", line: 2},
+					},
+				}
+
+				// ACT
+				md.writePanic("github.com/foo/package", pi)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<details><summary>💥 <b>panic:</b> assignment to entry in nil map</summary>",
+					"<pre>runtime.mapassign_faststr",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/go/src/runtime/map.go:1",
+					"<b>github.com/foo/package.TestFoo",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/src/package/foo_test.go:12</b>",
+					"testing.tRunner",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/go/src/testing/testing.go:2</pre>",
+					"</details>",
+					"",
+				})
+			},
+		},
+		{scenario: "tests/panic/timeout in module",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					module:       &module{path: "github.com/foo"},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pi := &panicinfo{
+					message: "test timed out after 1s",
+					timeout: true,
+					stack: []stackframe{
+						{function: "time.Sleep", file: "/go/src/runtime/time.go", line: 1},
+						{function: "github.com/foo/other.Wait", file: "/src/other/wait.go", line: 5},
+						{function: "github.com/foo/package.TestFoo", file: "/src/package/foo_test.go", line: 12},
+					},
+				}
+
+				// ACT
+				md.writePanic("github.com/foo/package", pi)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<details><summary>⌛ <b>test timed out after 1s</b></summary>",
+					"<pre>time.Sleep",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/go/src/runtime/time.go:1",
+					"<b>github.com/foo/other.Wait",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/src/other/wait.go:5</b>",
+					"github.com/foo/package.TestFoo",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/src/package/foo_test.go:12</pre>",
+					"</details>",
+					"",
+				})
+			},
+		},
+		{scenario: "tests/package failure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:    "github.com/foo/package",
+					elapsed: 1 * time.Millisecond,
+					failed:  true,
+					output:  []string{"TestMain: setup failed", "  connection refused"},
+					tests: []*testinfo{
+						{path: "Test1", result: trPassed, elapsed: 1 * time.Millisecond},
+					},
+				}
+
+				// ACT
+				md.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<tr>",
+					"  <td>🔴</td>",
+					"  <td colspan='2'><b>github.com/foo/package</b></td>",
+					"  <td align='right'>1ms</td>",
+					"</tr>",
+					"<tr valign='top'>",
+					"  <td></td>",
+					"  <td>🔴</td>",
+					"  <td>",
+					"    <b>package failure</b>",
+					"    <pre>TestMain:&nbsp;setup&nbsp;failed",
+					"&nbsp;&nbsp;connection&nbsp;refused</pre>",
+					"  </td>",
+					"  <td></td>",
+					"</tr>",
+					"",
+				})
+			},
+		},
+		{scenario: "tests/collapsed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmFailedTests,
+					collapse:     true,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:    "github.com/foo/package",
+					elapsed: 3 * time.Millisecond,
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond},
+						{path: "Test2", result: trPassed, elapsed: 2 * time.Millisecond},
+					},
+				}
+
+				// ACT
+				md.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<tr>",
+					"  <td>🔴</td>",
+					"  <td colspan='2'><b>github.com/foo/package</b></td>",
+					"  <td align='right'>3ms</td>",
+					"</tr>",
+					"<tr>",
+					"  <td></td>",
+					"  <td colspan=3>",
+					"    <details><summary><i>1 failed, 1 passed</i></summary>",
+					"    <table>",
+					"      <tr valign='top'>",
+					"        <td></td>",
+					"        <td>🔴</td>",
+					"        <td>",
+					"          <b>Test1</b>",
+					"        </td>",
+					"        <td align='right'>1ms</td>",
+					"      </tr>",
+					"    </table>",
+					"    </details>",
+					"  </td>",
+					"</tr>",
+					"",
+				})
+			},
+		},
+		{scenario: "tests/collapsed/summary",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					pkg    *packageinfo
+					result string
+				}{
+					{pkg: &packageinfo{buildFailed: true}, result: "build failed"},
+					{pkg: &packageinfo{}, result: "no tests"},
+					{pkg: &packageinfo{failed: true}, result: "package failure"},
+					{pkg: &packageinfo{failed: true, tests: []*testinfo{{result: trPassed}}}, result: "package failure, 1 passed"},
+					{pkg: &packageinfo{tests: []*testinfo{{result: trSkipped}, {result: trSkipped}}}, result: "2 skipped"},
+				}
+				for _, tc := range testcases {
+					test.That(t, markdown{}.packageSummary(tc.pkg)).Equals(tc.result)
+				}
+			},
+		},
+		{scenario: "tests/package failure/panic",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmFailedTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:    "github.com/foo/package",
+					elapsed: 1 * time.Millisecond,
+					failed:  true,
+					panic: &panicinfo{
+						message: "in init",
+						stack:   []stackframe{{function: "github.com/foo/package.init.0", file: "/src/package/foo.go", line: 4}},
+					},
+				}
+
+				// ACT
+				md.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<tr>",
+					"  <td>🔴</td>",
+					"  <td colspan='2'><b>github.com/foo/package</b></td>",
+					"  <td align='right'>1ms</td>",
+					"</tr>",
+					"<tr valign='top'>",
+					"  <td></td>",
+					"  <td>🔴</td>",
+					"  <td>",
+					"    <b>package failure</b>",
+					"    <details><summary>💥 <b>panic:</b> in init</summary>",
+					"    <pre><b>github.com/foo/package.init.0",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/src/package/foo.go:4</b></pre>",
+					"    </details>",
+					"  </td>",
+					"  <td></td>",
+					"</tr>",
+					"",
+				})
+			},
+		},
+
+		{scenario: "races/race in test",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				race := &raceinfo{
+					accesses: []racestack{
+						{description: "Write at 0x01 by goroutine 8", stack: []stackframe{
+							{function: "sync/atomic.AddInt32", file: "/go/src/sync/atomic/asm.s", line: 1},
+							{function: "github.com/foo/package.TestFoo.func1", file: "/src/package/foo_test.go", line: 8},
+						}},
+						{description: "Previous read at 0x01 by goroutine 7", stack: []stackframe{
+							{function: "github.com/foo/package.TestFoo", file: "/src/package/foo_test.go", line: 9},
+						}},
+					},
+					goroutines: []racestack{
+						{description: "Goroutine 8 (running) created at", stack: []stackframe{
+							{function: "github.com/foo/package.TestFoo", file: "/src/package/foo_test.go", line: 7},
+						}},
+					},
+				}
+
+				// ACT
+				md.writeRace("github.com/foo/package", "TestFoo", race)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<tr>",
+					"  <td>🏁</td>",
+					"  <td><b>TestFoo</b><br>github.com/foo/package</td>",
+					"</tr>",
+					"<tr valign='top'>",
+					"  <td></td>",
+					"  <td>",
+					"    <b>Write at 0x01 by goroutine 8</b>",
+					"    <pre>sync/atomic.AddInt32",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/go/src/sync/atomic/asm.s:1",
+					"<b>github.com/foo/package.TestFoo.func1",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/src/package/foo_test.go:8</b></pre>",
+					"    <b>Previous read at 0x01 by goroutine 7</b>",
+					"    <pre><b>github.com/foo/package.TestFoo",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/src/package/foo_test.go:9</b></pre>",
+					"    <details><summary>goroutines</summary>",
+					"    <b>Goroutine 8 (running) created at</b>",
+					"    <pre><b>github.com/foo/package.TestFoo",
+					"&nbsp;&nbsp;&nbsp;&nbsp;/src/package/foo_test.go:7</b></pre>",
+					"    </details>",
+					"  </td>",
+					"</tr>",
+					"",
+				})
+			},
+		},
+		{scenario: "races/section",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					IndentWriter: &IndentWriter{output: buf},
+					testrun: &testrun{
+						numRaces: 2,
+						packages: []*packageinfo{{
+							name:  "github.com/foo/package",
+							races: []*raceinfo{{}},
+							tests: []*testinfo{{path: "TestFoo", races: []*raceinfo{{}}}},
+						}},
+					},
+				}
+
+				// ACT
+				md.writeRaces()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"",
+					"### Data Races",
+					"",
+					"<table>",
+					"  <tr>",
+					"    <td>🏁</td>",
+					"    <td><b>github.com/foo/package</b></td>",
+					"  </tr>",
+					"  <tr valign='top'>",
+					"    <td></td>",
+					"    <td>",
+					"    </td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td>🏁</td>",
+					"    <td><b>TestFoo</b><br>github.com/foo/package</td>",
+					"  </tr>",
+					"  <tr valign='top'>",
+					"    <td></td>",
+					"    <td>",
+					"    </td>",
+					"  </tr>",
+					"</table>",
+					"",
+				})
+			},
+		},
+		{scenario: "summary/data races",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmSummaryOnly,
+					testrun:      &testrun{packages: []*packageinfo{{}}, numTests: 1, numFailed: 1, numRaces: 2},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+
+				// ACT
+				md.writeSummary()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					"  <tr>",
+					"    <td colspan=3 align='right'>🏁</td>",
+					"    <td>data races</td>",
+					"    <td align='right'>2</td>",
+					"  </tr>",
+				})
+			},
+		},
+		{scenario: "regressions/section",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					IndentWriter: &IndentWriter{output: buf},
+					regressions: []durationRegression{
+						{packageName: "pkg", baseline: 10 * time.Second, elapsed: 20 * time.Second},
+						{packageName: "pkg", path: "TestZero", elapsed: 1500 * time.Millisecond},
+					},
+				}
+
+				// ACT
+				md.writeRegressions()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"### Performance regressions",
+					"",
+					"<table>",
+					"  <tr>",
+					"    <th></th>",
+					"    <th></th>",
+					"    <th>baseline</th>",
+					"    <th>elapsed</th>",
+					"    <th>change</th>",
+					"  </tr>",
+					"  <tr>",
+					"    <td>📈</td>",
+					"    <td><b>pkg</b></td>",
+					"    <td align='right'>10s</td>",
+					"    <td align='right'>20s</td>",
+					"    <td align='right'>+10s (+100%)</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td>📈</td>",
+					"    <td><b>TestZero</b><br>pkg</td>",
+					"    <td align='right'>0s</td>",
+					"    <td align='right'>1.5s</td>",
+					"    <td align='right'>+1.5s</td>",
+					"  </tr>",
+					"</table>",
+					"",
+					"",
+				})
+			},
+		},
+		{scenario: "slowest/section",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					slowest:      2,
+					IndentWriter: &IndentWriter{output: buf},
+					testrun: &testrun{
+						numTests: 4,
+						packages: []*packageinfo{
+							{name: "pkga", elapsed: 2 * time.Second, tests: []*testinfo{
+								{path: "TestA1", packageName: "pkga", result: trPassed, elapsed: 500 * time.Millisecond},
+								{path: "TestA2", packageName: "pkga", result: trSkipped, elapsed: 5 * time.Second},
+							}},
+							{name: "pkgb", elapsed: 3 * time.Second, tests: []*testinfo{
+								{path: "TestB1", packageName: "pkgb", result: trFailed, elapsed: 1500 * time.Millisecond},
+								{path: "TestB2", packageName: "pkgb", result: trPassed, elapsed: 100 * time.Millisecond},
+							}},
+							{name: "pkgc", buildFailed: true},
+						},
+					},
+				}
+
+				// ACT
+				md.writeSlowest()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"",
+					"### Slowest Tests",
+					"",
+					"<table>",
+					"  <tr>",
+					"    <td></td>",
+					"    <td><b>TestB1</b><br>pkgb</td>",
+					"    <td align='right'>1.5s</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td></td>",
+					"    <td><b>TestA1</b><br>pkga</td>",
+					"    <td align='right'>500ms</td>",
+					"  </tr>",
+					"</table>",
+					"",
+					"### Slowest Packages",
+					"",
+					"<table>",
+					"  <tr>",
+					"    <td><b>pkgb</b></td>",
+					"    <td align='right'>3s</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td><b>pkga</b></td>",
+					"    <td align='right'>2s</td>",
+					"  </tr>",
+					"</table>",
+					"",
+				})
+			},
+		},
+		{scenario: "slowest/budget",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					slowest:      1,
+					budget:       200 * time.Millisecond,
+					IndentWriter: &IndentWriter{output: buf},
+					testrun: &testrun{
+						numTests: 3,
+						packages: []*packageinfo{
+							{name: "pkga", elapsed: 2 * time.Second, tests: []*testinfo{
+								{path: "TestA1", packageName: "pkga", result: trPassed, elapsed: 500 * time.Millisecond},
+								{path: "TestA2", packageName: "pkga", result: trPassed, elapsed: 300 * time.Millisecond},
+								{path: "TestA3", packageName: "pkga", result: trPassed, elapsed: 100 * time.Millisecond},
+							}},
+						},
+					},
+				}
+
+				// ACT
+				md.writeSlowest()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					"### Slowest Tests",
+					"",
+					"> 🐌 _2 of 3 tests exceeded the duration budget (200ms)_",
+					"",
+					"<table>",
+					"  <tr>",
+					"    <td>🐌</td>",
+					"    <td><b>TestA1</b><br>pkga</td>",
+					"    <td align='right'>500ms</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td>🐌</td>",
+					"    <td><b>TestA2</b><br>pkga</td>",
+					"    <td align='right'>300ms</td>",
+					"  </tr>",
+					"</table>",
+				})
+			},
+		},
+		{scenario: "slowest/budget only",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					budget:       time.Second,
+					IndentWriter: &IndentWriter{output: buf},
+					testrun: &testrun{
+						numTests: 1,
+						packages: []*packageinfo{
+							{name: "pkga", tests: []*testinfo{
+								{path: "TestA1", packageName: "pkga", result: trPassed, elapsed: 500 * time.Millisecond},
+							}},
+						},
+					},
+				}
+
+				// ACT
+				md.writeSlowest()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"",
+					"### Slowest Tests",
+					"",
+					"> 🐌 _0 of 1 tests exceeded the duration budget (1s)_",
+					"",
+				})
+			},
+		},
+		{scenario: "trend/section",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					IndentWriter: &IndentWriter{output: buf},
+					trend: []historyEntry{
+						{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Tests: 10, PercentPassed: 90, Elapsed: 1.25},
+						{Commit: "0123456789abcdef", Tests: 12, PercentPassed: 100, Elapsed: 2},
+					},
+				}
+
+				// ACT
+				md.writeTrend()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"",
+					"### Trend (last 2 runs)",
+					"",
+					"```mermaid",
+					"xychart-beta",
+					"  title \"pass rate\"",
+					"  x-axis [\"Jan 2 03:04\", \"0123456\"]",
+					"  y-axis \"%\" 0 --> 100",
+					"  line [90, 100]",
+					"```",
+					"",
+					"```mermaid",
+					"xychart-beta",
+					"  title \"tests\"",
+					"  x-axis [\"Jan 2 03:04\", \"0123456\"]",
+					"  y-axis \"tests\"",
+					"  bar [10, 12]",
+					"```",
+					"",
+					"```mermaid",
+					"xychart-beta",
+					"  title \"duration\"",
+					"  x-axis [\"Jan 2 03:04\", \"0123456\"]",
+					"  y-axis \"seconds\"",
+					"  line [1.2, 2.0]",
+					"```",
+					"",
+				})
+			},
+		},
+		{scenario: "trend/single run",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:    rmSummaryOnly,
+					trend:   []historyEntry{{Tests: 1}},
+					testrun: &testrun{},
+				}
+
+				// ACT
+				err := md.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.IsFalse(t, strings.Contains(buf.String(), "### Trend"), "trend section")
+			},
+		},
+		{scenario: "flaky/section",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					IndentWriter: &IndentWriter{output: buf},
+					testrun: &testrun{
+						numFlaky: 1,
+						packages: []*packageinfo{{
+							name: "github.com/foo/package",
+							tests: []*testinfo{
+								{path: "TestFlaky", result: trPassed, attempts: []testResult{trFailed, trPassed, trPassed}},
+								{path: "TestStable", result: trPassed, attempts: []testResult{trPassed, trPassed, trPassed}},
+							},
+						}},
+					},
+				}
+
+				// ACT
+				md.writeFlaky()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"",
+					"### Flaky Tests",
+					"",
+					"<table>",
+					"  <tr>",
+					"    <td>🎲</td>",
+					"    <td><b>TestFlaky</b><br>github.com/foo/package</td>",
+					"    <td>🔴✅✅</td>",
+					"    <td align='right'>1 of 3 failed</td>",
+					"  </tr>",
+					"</table>",
+					"",
+				})
+			},
+		},
+		{scenario: "summary/flaky",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmSummaryOnly,
+					testrun:      &testrun{packages: []*packageinfo{{}}, numTests: 1, numPassed: 1, numFlaky: 1},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+
+				// ACT
+				md.writeSummary()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					"  <tr>",
+					"    <td colspan=3 align='right'>🎲</td>",
+					"    <td>flaky</td>",
+					"    <td align='right'>1</td>",
+					"  </tr>",
+				})
+			},
+		},
+		{scenario: "tests/flaky",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmAllTests,
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:    "github.com/foo/package",
+					elapsed: 1 * time.Millisecond,
+					passed:  true,
+					tests: []*testinfo{
+						{path: "TestFlaky", result: trPassed, elapsed: 1 * time.Millisecond, attempts: []testResult{trFailed, trPassed}},
+					},
+				}
+
+				// ACT
+				md.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					"  <td>🎲</td>",
+					"  <td>",
+					"    <b>TestFlaky</b>",
+				})
+			},
+		},
+
+		{scenario: "tests/coverage",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				coverage := 87.5
+				md := &markdown{
+					mode:         rmFailedTests,
+					testrun:      &testrun{coverage: &coverage},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				pkg := &packageinfo{
+					name:     "github.com/foo/package",
+					elapsed:  1 * time.Millisecond,
+					coverage: &coverage,
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond},
+					},
+				}
+
+				// ACT
+				md.writePackage(pkg)
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<tr>",
+					"  <td>🔴</td>",
+					"  <td colspan='2'><b>github.com/foo/package</b></td>",
+					"  <td align='right'>87.5%</td>",
+					"  <td align='right'>1ms</td>",
+					"</tr>",
+					"<tr valign='top'>",
+					"  <td></td>",
+					"  <td>🔴</td>",
+					"  <td>",
+					"    <b>Test1</b>",
+					"  </td>",
+					"  <td></td>",
+					"  <td align='right'>1ms</td>",
+					"</tr>",
+					"",
+				})
+			},
+		},
+		{scenario: "summary/coverage",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				coverage := 87.54
+				md := &markdown{
+					mode:         rmSummaryOnly,
+					testrun:      &testrun{packages: []*packageinfo{{}}, numTests: 1, numPassed: 1, percentPassed: 100, coverage: &coverage},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+
+				// ACT
+				md.writeSummary()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					"  <tr>",
+					"    <td colspan=3 align='right'>📊</td>",
+					"    <td>coverage</td>",
+					"    <td align='right'>87.5%</td>",
+					"  </tr>",
+				})
+			},
+		},
+
+		// detail tests
+		{scenario: "detail/1 package, 1 failed test, 1 passed (failed tests mode)",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmFailedTests,
+					testrun:      &testrun{},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				md.testrun.packages = []*packageinfo{{}}
+				md.testrun.numTests = 2
+				md.testrun.numFailed = 1
+				md.testrun.numPassed = 1
+				md.testrun.percentPassed = 50
+				md.testrun.packages = []*packageinfo{{
+					name:    "github.com/foo/package",
+					elapsed: 3 * time.Millisecond,
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond},
+						{path: "Test2", result: trPassed, elapsed: 2 * time.Millisecond},
+					},
+				}}
+
+				// ACT
+				md.writeDetail()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"<table>",
+					"  <tr>",
+					"    <td>🔴</td>",
+					"    <td colspan='2'><b>github.com/foo/package</b></td>",
+					"    <td align='right'>3ms</td>",
+					"  </tr>",
+					"  <tr valign='top'>",
+					"    <td></td>",
+					"    <td>🔴</td>",
+					"    <td>",
+					"      <b>Test1</b>",
+					"    </td>",
+					"    <td align='right'>1ms</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td>✅</td>",
+					"    <td colspan=3><b>1 test passed</b></td>",
+					"  </tr>",
+					"</table>",
+					"",
+				})
+			},
+		},
+		{scenario: "detail/1 package, 1 failed test, 2 passed (failed tests mode)",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmFailedTests,
+					testrun:      &testrun{},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				md.testrun.packages = []*packageinfo{{}}
+				md.testrun.numTests = 3
+				md.testrun.numFailed = 1
+				md.testrun.numPassed = 2
+				md.testrun.percentPassed = 66
+				md.testrun.packages = []*packageinfo{{
+					name:    "github.com/foo/package",
+					elapsed: 6 * time.Millisecond,
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, elapsed: 1 * time.Millisecond},
+						{path: "Test2", result: trPassed, elapsed: 2 * time.Millisecond},
+						{path: "Test3", result: trPassed, elapsed: 3 * time.Millisecond},
+					},
+				}}
 
 				// ACT
 				md.writeDetail()
@@ -439,7 +1626,323 @@ func TestMarkdown(t *testing.T) {
 			},
 		},
 
+		// benchmark tests
+		{scenario: "benchmarks/1 package, 2 benchmarks",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode:         rmFailedTests,
+					testrun:      &testrun{},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+				bytesPerOp, allocsPerOp := 16.0, 1.0
+				md.testrun.numBenchmarks = 2
+				md.testrun.packages = []*packageinfo{
+					{name: "github.com/foo/nobenchmarks"},
+					{name: "github.com/foo/package",
+						benchmarks: []*benchmark{
+							{name: "BenchmarkFoo", iterations: 1000, nsPerOp: 249.5},
+							{name: "BenchmarkBar", iterations: 10, nsPerOp: 1234, bytesPerOp: &bytesPerOp, allocsPerOp: &allocsPerOp,
+								metrics: map[string]float64{"widgets/op": 42, "MB/s": 20.5},
+							},
+							{name: "BenchmarkFails", failed: true},
+						},
+					},
+				}
+
+				// ACT
+				md.writeBenchmarks()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"",
+					"### Benchmarks",
+					"",
+					"<table>",
+					"  <tr>",
+					"    <th></th>",
+					"    <th>benchmark</th>",
+					"    <th>iterations</th>",
+					"    <th>ns/op</th>",
+					"    <th>B/op</th>",
+					"    <th>allocs/op</th>",
+					"    <th>metrics</th>",
+					"  </tr>",
+					"  <tr>",
+					"    <td colspan=7><b>github.com/foo/package</b></td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td>✅</td>",
+					"    <td>BenchmarkFoo</td>",
+					"    <td align='right'>1000</td>",
+					"    <td align='right'>249.5</td>",
+					"    <td align='right'></td>",
+					"    <td align='right'></td>",
+					"    <td></td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td>✅</td>",
+					"    <td>BenchmarkBar</td>",
+					"    <td align='right'>10</td>",
+					"    <td align='right'>1234</td>",
+					"    <td align='right'>16</td>",
+					"    <td align='right'>1</td>",
+					"    <td>20.5 MB/s<br>42 widgets/op</td>",
+					"  </tr>",
+					"  <tr>",
+					"    <td>🔴</td>",
+					"    <td>BenchmarkFails</td>",
+					"    <td align='right'>0</td>",
+					"    <td align='right'>0</td>",
+					"    <td align='right'></td>",
+					"    <td align='right'></td>",
+					"    <td></td>",
+					"  </tr>",
+					"</table>",
+					"",
+				})
+			},
+		},
+
+		// changes tests
+		{scenario: "changes/no changes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					changes:      changes{},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+
+				// ACT
+				md.writeChanges()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"### Changes since baseline",
+					"",
+					"_no changes_",
+					"",
+					"",
+				})
+			},
+		},
+		{scenario: "changes",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					changes: changes{
+						tcNewFailure: {
+							{packageName: "github.com/foo/pkg", path: "Test1"},
+							{packageName: "github.com/foo/pkg", path: "Test2"},
+						},
+						tcFixed:   {{packageName: "github.com/foo/pkg", path: "Test3"}},
+						tcRemoved: {{packageName: "github.com/foo/other", path: "Test4"}},
+					},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+
+				// ACT
+				md.writeChanges()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"### Changes since baseline",
+					"",
+					"<table>",
+					"  <tr valign='top'>",
+					"    <td>🔴</td>",
+					"    <td>",
+					"      <details open><summary><b>2 new failures</b></summary>",
+					"      <div><b>Test1</b> <i>github.com/foo/pkg</i></div>",
+					"      <div><b>Test2</b> <i>github.com/foo/pkg</i></div>",
+					"      </details>",
+					"    </td>",
+					"  </tr>",
+					"  <tr valign='top'>",
+					"    <td>✅</td>",
+					"    <td>",
+					"      <details><summary><b>1 fixed</b></summary>",
+					"      <div><b>Test3</b> <i>github.com/foo/pkg</i></div>",
+					"      </details>",
+					"    </td>",
+					"  </tr>",
+					"  <tr valign='top'>",
+					"    <td>➖</td>",
+					"    <td>",
+					"      <details><summary><b>1 removed</b></summary>",
+					"      <div><b>Test4</b> <i>github.com/foo/other</i></div>",
+					"      </details>",
+					"    </td>",
+					"  </tr>",
+					"</table>",
+					"",
+					"",
+				})
+			},
+		},
+		{scenario: "changes/summary only",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{
+					mode: rmSummaryOnly,
+					changes: changes{
+						tcNewFailure:   {{packageName: "github.com/foo/pkg", path: "Test1"}},
+						tcStillFailing: {{packageName: "github.com/foo/pkg", path: "Test2"}},
+						tcAdded:        {{packageName: "github.com/foo/pkg", path: "Test3"}},
+					},
+					IndentWriter: &IndentWriter{output: buf},
+				}
+
+				// ACT
+				md.writeChanges()
+
+				// ASSERT
+				test.Strings(t, buf.Bytes()).Equals([]string{
+					"### Changes since baseline",
+					"",
+					"<table>",
+					"  <tr valign='top'>",
+					"    <td>🔴</td>",
+					"    <td><b>1 new failure</b></td>",
+					"  </tr>",
+					"  <tr valign='top'>",
+					"    <td>🔁</td>",
+					"    <td><b>1 still failing</b></td>",
+					"  </tr>",
+					"  <tr valign='top'>",
+					"    <td>➕</td>",
+					"    <td><b>1 added</b></td>",
+					"  </tr>",
+					"</table>",
+					"",
+					"",
+				})
+			},
+		},
+
 		// export tests
+		{scenario: "export/max size",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				output := []string{}
+				for i := 0; i < 100; i++ {
+					output = append(output, strings.Repeat("x", 100))
+				}
+				newReport := func(maxSize int) *markdown {
+					tr := &testrun{packages: []*packageinfo{{
+						name: "github.com/foo/package",
+						tests: []*testinfo{
+							{path: "Test1", result: trFailed, output: map[string][]string{"foo_test.go:1": output}},
+							{path: "Test2", result: trPassed, output: map[string][]string{"foo_test.go:2": output}},
+						},
+					}}}
+					tr.recount()
+					return &markdown{mode: rmAllTests, title: "Test Report", maxSize: maxSize, testrun: tr}
+				}
+
+				testcases := []struct {
+					maxSize int
+					reduced []string
+				}{
+					{maxSize: 0},
+					{maxSize: -1},
+					{maxSize: 8000, reduced: []string{"output truncated to 20 lines"}},
+					{maxSize: 4000, reduced: []string{"output truncated to 20 lines", "passed and skipped tests omitted"}},
+					{maxSize: 2000, reduced: []string{"output truncated to 20 lines", "passed and skipped tests omitted", "output truncated to 5 lines"}},
+					{maxSize: 1500, reduced: []string{"output truncated to 20 lines", "passed and skipped tests omitted", "output truncated to 5 lines", "output and stacks omitted"}},
+					{maxSize: 700, reduced: []string{"output truncated to 20 lines", "passed and skipped tests omitted", "output truncated to 5 lines", "output and stacks omitted", "packages collapsed", "details omitted"}},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%d", tc.maxSize), func(t *testing.T) {
+						buf := bytes.NewBuffer(nil)
+						md := newReport(tc.maxSize)
+
+						// ACT
+						err := md.export(buf)
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, md.reduced).Equals(tc.reduced)
+						if tc.maxSize > 0 {
+							test.IsTrue(t, buf.Len() <= tc.maxSize, "report fits")
+						}
+						if tc.reduced != nil {
+							test.Strings(t, buf.Bytes()).Contains([]string{
+								"> ❗ _report reduced to fit the maximum size (" + fmt.Sprint(tc.maxSize) + " bytes): " + strings.Join(tc.reduced, ", ") + "_",
+							})
+						}
+					})
+				}
+			},
+		},
+		{scenario: "export/max size/exceeded",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{title: "Test Report", maxSize: 10, testrun: &testrun{}}
+
+				// ACT
+				err := md.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(md.reduced)).Equals(0, "nothing to reduce")
+				test.IsFalse(t, strings.Contains(buf.String(), "report reduced"), "report reduced note")
+			},
+		},
+		{scenario: "export/max size/output not truncated",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				output := []string{}
+				for i := 0; i < 10; i++ {
+					output = append(output, strings.Repeat("x", 100))
+				}
+				tr := &testrun{packages: []*packageinfo{{
+					name: "github.com/foo/package",
+					tests: []*testinfo{
+						{path: "Test1", result: trFailed, output: map[string][]string{"foo_test.go:1": output}},
+						{path: "Test2", result: trPassed, output: map[string][]string{"foo_test.go:2": output}},
+					},
+				}}}
+				tr.recount()
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{mode: rmAllTests, title: "Test Report", maxSize: 2000, testrun: tr}
+
+				// ACT
+				err := md.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, md.reduced).Equals([]string{"passed and skipped tests omitted"})
+			},
+		},
+		{scenario: "export/max size/packages collapsed",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tests := []*testinfo{}
+				for i := 0; i < 20; i++ {
+					tests = append(tests, &testinfo{path: fmt.Sprintf("Test%d", i), result: trFailed})
+				}
+				tr := &testrun{packages: []*packageinfo{{name: "github.com/foo/package", tests: tests}}}
+				tr.recount()
+				buf := bytes.NewBuffer(nil)
+				md := &markdown{title: "Test Report", maxSize: 1000, testrun: tr}
+
+				// ACT
+				err := md.export(buf)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, md.reduced).Equals([]string{"packages collapsed"})
+				test.Strings(t, buf.Bytes()).Contains([]string{
+					"    <td colspan=3><i>20 failed</i></td>",
+				})
+			},
+		},
 		{scenario: "export/1 package, 1 failed test, 1 skipped, 1 passed (summary only)",
 			exec: func(t *testing.T) {
 				// ARRANGE
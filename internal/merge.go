@@ -0,0 +1,152 @@
+package internal
+
+import "slices"
+
+// mergeReports is a command that generates a report by merging the test runs
+// in a number of inputs, e.g. the logs of tests sharded across several CI
+// jobs.
+//
+// Unlike generateReport, each input is parsed separately and the resulting
+// testruns merged; a package appearing in more than one input is reported
+// once, with the tests from each input.
+type mergeReports struct {
+	generateReport
+}
+
+// Run is a method that generates a report from merged inputs.
+func (cmd mergeReports) Run(opts *Options) int {
+	td := &testrun{}
+	if !cmd.checkError(cmd.read(td)) {
+		return 1
+	}
+	return cmd.write(td)
+}
+
+// read parses each input file into a separate testrun and merges them into
+// the specified testrun.  At least one input must be specified; merged
+// inputs cannot be read from stdin.
+func (cmd mergeReports) read(td *testrun) error {
+	if len(cmd.inputs) == 0 {
+		return ErrNoInputs
+	}
+
+	filenames, err := inputFiles(cmd.inputs)
+	if err != nil {
+		return err
+	}
+
+	runs := make([]*testrun, 0, len(filenames))
+	for _, filename := range filenames {
+		run := &testrun{}
+		if err := cmd.readFile(filename, run); err != nil {
+			return err
+		}
+		runs = append(runs, run)
+	}
+
+	*td = *merge(runs...)
+	return nil
+}
+
+// readFile parses a single input file into a testrun.
+func (cmd mergeReports) readFile(filename string, td *testrun) error {
+	return cmd.parser.parseFiles([]string{filename}, td)
+}
+
+// merge combines a number of testruns into a single testrun.
+//
+// Packages are identified by name; a package in more than one testrun is
+// merged into a single package (see mergePackage).  Since the testruns are
+// assumed to have run concurrently, the elapsed time of the merged testrun
+// is that of the longest testrun.
+func merge(runs ...*testrun) *testrun {
+	result := &testrun{}
+	pkgs := map[string]*packageinfo{}
+
+	for _, run := range runs {
+		result.elapsed = max(result.elapsed, run.elapsed)
+		for _, p := range run.packages {
+			if pkg, ok := pkgs[p.name]; ok {
+				mergePackage(pkg, p)
+				continue
+			}
+			pkg := *p
+			pkg.tests = slices.Clone(p.tests)
+			pkg.benchmarks = slices.Clone(p.benchmarks)
+			pkgs[p.name] = &pkg
+			result.packages = append(result.packages, &pkg)
+		}
+	}
+
+	result.recount()
+	return result
+}
+
+// mergePackage merges the tests and benchmarks of package src into dest.
+//
+// Each input runs a separate subset of the tests in a package, so the
+// elapsed time of the package is the total elapsed time across all inputs.
+// The merged package passed only if it passed in every input.  Build
+// output and any panic not attributed to a test are taken from the first
+// input in which they occur; package output and data races from each input
+// are combined.
+//
+// The coverage of the merged package is the highest coverage reported in
+// any input (the coverage of tests run in different inputs cannot be
+// combined without a coverage profile).
+//
+// The merged package is a package failure (see packageinfo.failed) if the
+// package failed in any input but no test failed (in any attempt) in the
+// merged package.
+//
+// A test appearing in both packages with different results is flagged as
+// a conflict and reported with the "worst" result (failed, then passed,
+// then skipped) together with the output from that result.
+func mergePackage(dest, src *packageinfo) {
+	dest.passed = dest.passed && src.passed
+	dest.elapsed += src.elapsed
+	if src.buildFailed && !dest.buildFailed {
+		dest.buildFailed = true
+		dest.buildOutput = src.buildOutput
+	}
+	if dest.panic == nil {
+		dest.panic = src.panic
+	}
+	dest.output = append(slices.Clip(dest.output), src.output...)
+	dest.races = append(slices.Clip(dest.races), src.races...)
+	if src.coverage != nil && (dest.coverage == nil || *src.coverage > *dest.coverage) {
+		dest.coverage = src.coverage
+	}
+
+	rank := map[testResult]int{
+		trFailed:  0,
+		trPassed:  1,
+		trSkipped: 2,
+	}
+	for _, t := range src.tests {
+		i := slices.IndexFunc(dest.tests, func(dt *testinfo) bool { return dt.path == t.path })
+		if i == -1 {
+			dest.tests = append(dest.tests, t)
+			continue
+		}
+
+		dt := dest.tests[i]
+		if dt.result == t.result {
+			continue
+		}
+		merged := dt
+		if rank[t.result] < rank[dt.result] {
+			merged = t
+		}
+		merged.conflict = true
+		dest.tests[i] = merged
+	}
+
+	for _, b := range src.benchmarks {
+		if !slices.ContainsFunc(dest.benchmarks, func(db *benchmark) bool { return db.name == b.name }) {
+			dest.benchmarks = append(dest.benchmarks, b)
+		}
+	}
+
+	dest.failed = (dest.failed || src.failed) && !slices.ContainsFunc(dest.tests, (*testinfo).failedAny)
+}
@@ -0,0 +1,265 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestMerge(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "distinct packages",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a := &testrun{elapsed: 2 * time.Second, packages: []*packageinfo{
+					{name: "a", passed: true, tests: []*testinfo{{path: "Test1", result: trPassed}}},
+				}}
+				b := &testrun{elapsed: 3 * time.Second, packages: []*packageinfo{
+					{name: "b", tests: []*testinfo{{path: "Test1", result: trFailed}}},
+				}}
+
+				// ACT
+				result := merge(a, b)
+
+				// ASSERT
+				test.That(t, result.elapsed).Equals(3 * time.Second)
+				test.That(t, len(result.packages)).Equals(2)
+				test.That(t, result.numTests).Equals(2)
+				test.That(t, result.numPassed).Equals(1)
+				test.That(t, result.numFailed).Equals(1)
+				test.That(t, result.percentPassed).Equals(50)
+				test.That(t, result.numConflicts).Equals(0)
+			},
+		},
+		{scenario: "package in more than one input",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a := &testrun{packages: []*packageinfo{
+					{name: "a", passed: true, elapsed: 2 * time.Second, tests: []*testinfo{
+						{path: "Test1", result: trPassed},
+					}},
+				}}
+				b := &testrun{packages: []*packageinfo{
+					{name: "a", passed: true, elapsed: 3 * time.Second, tests: []*testinfo{
+						{path: "Test1", result: trPassed},
+						{path: "Test2", result: trPassed},
+					}},
+				}}
+
+				// ACT
+				result := merge(a, b)
+
+				// ASSERT
+				test.That(t, len(result.packages)).Equals(1)
+				pkg := result.packages[0]
+				test.IsTrue(t, pkg.passed)
+				test.That(t, pkg.elapsed).Equals(5 * time.Second)
+				test.That(t, len(pkg.tests)).Equals(2)
+				test.That(t, result.numTests).Equals(2)
+				test.That(t, result.numConflicts).Equals(0)
+				test.That(t, len(a.packages[0].tests)).Equals(1, "tests in original package")
+			},
+		},
+		{scenario: "conflicting results",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a := &testrun{packages: []*packageinfo{
+					{name: "a", passed: true, tests: []*testinfo{
+						{path: "Test1", result: trPassed},
+						{path: "Test2", result: trSkipped},
+					}},
+				}}
+				b := &testrun{packages: []*packageinfo{
+					{name: "a", tests: []*testinfo{
+						{path: "Test1", result: trFailed, output: map[string][]string{"a_test.go:10": {"failed"}}},
+						{path: "Test2", result: trPassed},
+					}},
+				}}
+
+				// ACT
+				result := merge(a, b)
+
+				// ASSERT
+				pkg := result.packages[0]
+				test.IsFalse(t, pkg.passed)
+				test.That(t, pkg.tests[0].result).Equals(trFailed)
+				test.IsTrue(t, pkg.tests[0].conflict)
+				test.Map(t, pkg.tests[0].output).Equals(map[string][]string{"a_test.go:10": {"failed"}})
+				test.That(t, pkg.tests[1].result).Equals(trPassed)
+				test.IsTrue(t, pkg.tests[1].conflict)
+				test.That(t, result.numFailed).Equals(1)
+				test.That(t, result.numPassed).Equals(1)
+				test.That(t, result.numSkipped).Equals(0)
+				test.That(t, result.numConflicts).Equals(2)
+			},
+		},
+		{scenario: "build failure and benchmarks",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a := &testrun{packages: []*packageinfo{
+					{name: "a", passed: true, benchmarks: []*benchmark{{name: "Benchmark1"}}},
+				}}
+				b := &testrun{packages: []*packageinfo{
+					{name: "a", buildFailed: true, buildOutput: []string{"a.go:1:1: error"},
+						benchmarks: []*benchmark{{name: "Benchmark1"}, {name: "Benchmark2"}},
+					},
+				}}
+
+				// ACT
+				result := merge(a, b)
+
+				// ASSERT
+				pkg := result.packages[0]
+				test.IsTrue(t, pkg.buildFailed)
+				test.Strings(t, pkg.buildOutput).Equals([]string{"a.go:1:1: error"})
+				test.That(t, len(pkg.benchmarks)).Equals(2)
+				test.That(t, result.numBuildFailed).Equals(1)
+				test.That(t, result.numBenchmarks).Equals(2)
+			},
+		},
+		{scenario: "package failure",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				a := &testrun{packages: []*packageinfo{
+					{name: "a", failed: true, output: []string{"a"}, tests: []*testinfo{{path: "Test1", result: trPassed}}},
+					{name: "b", failed: true, output: []string{"b"}, tests: []*testinfo{{path: "Test1", result: trPassed}}},
+				}}
+				b := &testrun{packages: []*packageinfo{
+					{name: "a", output: []string{"c"}, tests: []*testinfo{{path: "Test2", result: trPassed}}},
+					{name: "b", tests: []*testinfo{{path: "Test2", result: trFailed}}},
+				}}
+
+				// ACT
+				result := merge(a, b)
+
+				// ASSERT
+				test.IsTrue(t, result.packages[0].failed, "package a failed")
+				test.Strings(t, result.packages[0].output).Equals([]string{"a", "c"})
+				test.Strings(t, a.packages[0].output).Equals([]string{"a"})
+				test.IsFalse(t, result.packages[1].failed, "package b failed (with failed test)")
+				test.That(t, result.numPackageFailed).Equals(1)
+			},
+		},
+		{scenario: "package panic",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				pi := &panicinfo{message: "in init"}
+				a := &testrun{packages: []*packageinfo{{name: "a", passed: true}}}
+				b := &testrun{packages: []*packageinfo{{name: "a", panic: pi}}}
+
+				// ACT
+				result := merge(a, b)
+
+				// ASSERT
+				test.IsFalse(t, result.packages[0].passed)
+				test.IsTrue(t, result.packages[0].panic == pi, "package panic")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
+
+func TestMergeReportsRun(t *testing.T) {
+	// ARRANGE
+	exitCode := 0
+	defer test.Using(&osExit, func(code int) {
+		exitCode = code
+	})()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no inputs",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				exitCode = 0
+				sut := mergeReports{generateReport{parser: &parser{}}}
+
+				// ACT
+				stdout, _ := test.CaptureOutput(t, func() {
+					_ = sut.Run(&Options{})
+				})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+				stdout.Contains(ErrNoInputs.Error())
+			},
+		},
+		{scenario: "input file not found",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				exitCode = 0
+				sut := mergeReports{generateReport{
+					inputs: []string{filepath.Join(t.TempDir(), "missing.json")},
+					parser: &parser{},
+				}}
+
+				// ACT
+				_ = sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "success/shards",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				dir := t.TempDir()
+				_ = os.WriteFile(filepath.Join(dir, "shard-1.json"), []byte(
+					`{"Action":"start","Package":"a"}
+{"Action":"run","Package":"a","Test":"Test1"}
+{"Action":"pass","Package":"a","Test":"Test1","Elapsed":0.1}
+{"Action":"pass","Package":"a","Elapsed":1}
+`), 0o644)
+				_ = os.WriteFile(filepath.Join(dir, "shard-2.json"), []byte(
+					`{"Action":"start","Package":"a"}
+{"Action":"run","Package":"a","Test":"Test2"}
+{"Action":"fail","Package":"a","Test":"Test2","Elapsed":0.1}
+{"Action":"fail","Package":"a","Elapsed":2}
+`), 0o644)
+
+				defer test.Using(&osCreate, func(name string) (*os.File, error) {
+					return &os.File{}, nil
+				})()
+				var td *testrun
+				defer test.Using(&mdExport, func(md *markdown, w io.Writer) error {
+					td = md.testrun
+					return nil
+				})()
+
+				sut := mergeReports{generateReport{
+					inputs: []string{filepath.Join(dir, "shard-*.json")},
+					parser: &parser{},
+				}}
+
+				// ACT
+				result := sut.Run(&Options{})
+
+				// ASSERT
+				test.That(t, result).Equals(-1)
+				test.That(t, len(td.packages)).Equals(1)
+				test.That(t, td.packages[0].elapsed).Equals(3 * time.Second)
+				test.That(t, td.numTests).Equals(2)
+				test.That(t, td.numFailed).Equals(1)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
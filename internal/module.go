@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// osStat is a function variable to facilitate testing.
+var osStat = os.Stat
+
+// module identifies the module tested, used to locate the files referenced
+// in test output.
+type module struct {
+	path    string // the module path (from go.mod)
+	dir     string // the module directory
+	repoDir string // the module directory, relative to the repository root ("" if the module is the root)
+}
+
+// loadModule returns the module in the specified directory (or the current
+// directory if not specified).  If the current directory does not contain a
+// go.mod file, nil is returned; if a specified directory does not contain a
+// go.mod file, an error is returned.
+//
+// The repository root is the nearest directory, at or above the module
+// directory, containing a .git folder; if no such directory is found the
+// module directory is assumed to be the repository root.
+func loadModule(root string) (*module, error) {
+	dir, err := filepath.Abs(coalesce(root, "."))
+	if err != nil {
+		return nil, err
+	}
+
+	gomod, err := osReadFile(filepath.Join(dir, "go.mod"))
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && root == "":
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	path := regexp.MustCompile(`(?m)^module\s+"?([^\s"]+)"?`).FindSubmatch(gomod)
+	if path == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoModulePath, filepath.Join(dir, "go.mod"))
+	}
+
+	mod := &module{
+		path: string(path[1]),
+		dir:  dir,
+	}
+	for root := dir; ; root = filepath.Dir(root) {
+		if _, err := osStat(filepath.Join(root, ".git")); err == nil {
+			if rel, _ := filepath.Rel(root, dir); rel != "." {
+				mod.repoDir = filepath.ToSlash(rel)
+			}
+			break
+		}
+		if filepath.Dir(root) == root {
+			break
+		}
+	}
+	return mod, nil
+}
+
+// file returns the (slash separated) path, relative to the module
+// directory, of a file in the specified package.  If the package is not in
+// the module, or the file does not exist in the package directory (e.g. a
+// file in the standard library, referenced in the output of a test), an
+// empty string is returned.
+func (m *module) file(pkg string, file string) string {
+	switch {
+	case pkg == m.path:
+	case strings.HasPrefix(pkg, m.path+"/"):
+		file = path.Join(strings.TrimPrefix(pkg, m.path+"/"), file)
+	default:
+		return ""
+	}
+	if _, err := osStat(filepath.Join(m.dir, filepath.FromSlash(file))); err != nil {
+		return ""
+	}
+	return file
+}
+
+// splitRef splits a source reference ("<filename>:<line #>") into the
+// filename and line number.  ok is false if the reference is not valid.
+func splitRef(ref string) (file string, line string, ok bool) {
+	i := strings.LastIndex(ref, ":")
+	if i == -1 {
+		return "", "", false
+	}
+	return ref[:i], ref[i+1:], true
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestModule(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "load/no go.mod",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) { return nil, fs.ErrNotExist })()
+
+				// ACT
+				result, err := loadModule("")
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "load/no go.mod in module root",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) { return nil, fs.ErrNotExist })()
+
+				// ACT
+				result, err := loadModule("module")
+
+				// ASSERT
+				test.Error(t, err).Is(fs.ErrNotExist)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "load/no module path",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osReadFile, func(string) ([]byte, error) { return []byte("go 1.21\n"), nil })()
+
+				// ACT
+				result, err := loadModule("")
+
+				// ASSERT
+				test.Error(t, err).Is(ErrNoModulePath)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "load/module in repository sub-directory",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				repo := t.TempDir()
+				dir := filepath.Join(repo, "modules", "foo")
+				_ = os.MkdirAll(dir, 0o755)
+				_ = os.Mkdir(filepath.Join(repo, ".git"), 0o755)
+				_ = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/foo/repo/modules/foo\n\ngo 1.21\n"), 0o644)
+
+				// ACT
+				result, err := loadModule(dir)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, *result).Equals(module{
+					path:    "github.com/foo/repo/modules/foo",
+					dir:     dir,
+					repoDir: "modules/foo",
+				})
+			},
+		},
+		{scenario: "load/module at repository root",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				repo := t.TempDir()
+				_ = os.Mkdir(filepath.Join(repo, ".git"), 0o755)
+				_ = os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module github.com/foo/repo\n"), 0o644)
+
+				// ACT
+				result, err := loadModule(repo)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, result.repoDir).Equals("")
+			},
+		},
+		{scenario: "file",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				stats := []string{}
+				defer test.Using(&osStat, func(name string) (fs.FileInfo, error) {
+					stats = append(stats, filepath.ToSlash(name))
+					if filepath.Base(name) == "testing.go" {
+						return nil, fs.ErrNotExist
+					}
+					return nil, nil
+				})()
+				mod := &module{path: "github.com/foo/repo", dir: "/src/repo"}
+
+				// ACT & ASSERT
+				test.That(t, mod.file("github.com/foo/repo", "foo_test.go")).Equals("foo_test.go")
+				test.That(t, mod.file("github.com/foo/repo/internal/bar", "bar_test.go")).Equals("internal/bar/bar_test.go")
+				test.That(t, mod.file("github.com/foo/repository", "foo_test.go")).Equals("")
+				test.That(t, mod.file("github.com/foo/repo/internal/bar", "testing.go")).Equals("")
+				test.That(t, stats).Equals([]string{
+					"/src/repo/foo_test.go",
+					"/src/repo/internal/bar/bar_test.go",
+					"/src/repo/internal/bar/testing.go",
+				})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
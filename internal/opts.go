@@ -2,7 +2,9 @@ package internal
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"time"
 )
 
 // parseFlags if a variable function that parses the command line arguments.
@@ -18,36 +20,195 @@ type Options struct{}
 // appropriate command to run (if any).
 func (o *Options) Parse() (interface{ Run(*Options) int }, error) {
 	opts := struct {
-		h, help    bool
+		allowFlaky bool
+		baseline   string
+		budget     time.Duration
+		c, config  string
+		collapse   bool
+		cover      string
 		f, full    bool
+		format     string
+		github     bool
+		h, help    bool
+		history    string
+		runs       int
+		maxLines   int
+		maxSize    int
+		moduleRoot string
+		policy     exitPolicy
+		regression regressionThreshold
 		o, output  string
 		s, summary bool
+		slowest    int
+		snippets   int
+		sourceURL  string
 		t, title   string
 		v, verbose bool
-	}{}
-	if len(os.Args) > 1 {
-		if os.Args[1] == "version" {
+		orange     int
+		yellow     int
+		inputs     []string
+		isSet      map[string]bool
+	}{
+		isSet: map[string]bool{},
+	}
+	args := os.Args[1:]
+	merge := false
+	if len(args) > 0 {
+		switch args[0] {
+		case "version":
 			return showVersion{}, nil
+		case "merge":
+			merge = true
+			args = args[1:]
 		}
-
+	}
+	if len(args) > 0 {
 		flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+		flags.BoolVar(&opts.allowFlaky, "allow-flaky", false, "a package that failed only due to flaky tests is not a failure")
+		flags.StringVar(&opts.baseline, "baseline", "", "baseline go test -json log or json report")
+		flags.StringVar(&opts.c, "c", "", "configuration file")
+		flags.BoolVar(&opts.collapse, "collapse", false, "collapsible test output and packages")
+		flags.StringVar(&opts.config, "config", "", "")
+		flags.StringVar(&opts.cover, "coverprofile", "", "coverage profile")
+		flags.DurationVar(&opts.budget, "duration-budget", 0, "duration budget of a test")
 		flags.BoolVar(&opts.f, "f", false, "complete test report")
+		flags.BoolVar(&opts.policy.failOnBuildFailure, "fail-on-build-failure", false, "exit with an error if a package failed to build")
+		flags.BoolVar(&opts.policy.failOnFlaky, "fail-on-flaky", false, "exit with an error if any tests were flaky")
+		flags.BoolVar(&opts.policy.failOnNoTests, "fail-on-no-tests", false, "exit with an error if there are no tests")
+		flags.BoolVar(&opts.policy.failOnRegression, "fail-on-regression", false, "exit with an error if tests or packages took longer than in the baseline")
+		flags.BoolVar(&opts.policy.failOnSkip, "fail-on-skip", false, "exit with an error if any tests were skipped")
 		flags.BoolVar(&opts.full, "full", false, "")
+		flags.StringVar(&opts.format, "format", "markdown", "report format")
+		flags.BoolVar(&opts.github, "github", false, "GitHub Actions job summary and annotations")
 		flags.BoolVar(&opts.h, "h", false, "display help on usage")
 		flags.BoolVar(&opts.help, "help", false, "")
+		flags.StringVar(&opts.history, "history", "", "history file to append the testrun to (and report trends from)")
+		flags.IntVar(&opts.runs, "history-runs", defaultHistoryRuns, "number of runs presented in a trend")
+		flags.StringVar(&opts.moduleRoot, "module-root", "", "directory containing the go.mod of the module tested")
+		flags.IntVar(&opts.policy.maxFailed, "max-failed", 0, "maximum number of failed tests (-1: no maximum)")
+		flags.IntVar(&opts.maxLines, "max-lines", 0, "maximum lines of each block of output (0: no limit)")
+		flags.IntVar(&opts.maxSize, "max-size", 0, "maximum size of the report in bytes (0: 1 MiB; -1: no limit)")
+		flags.IntVar(&opts.policy.minPassRate, "min-pass-rate", 0, "minimum pass rate %age")
+		flags.IntVar(&opts.orange, "orange-threshold", defaultThresholds.orange, "pass rate %age for an orange report icon")
 		flags.StringVar(&opts.o, "o", "", "output filename")
 		flags.StringVar(&opts.output, "output", "", "")
+		flags.DurationVar(&opts.regression.min, "regression-min", defaultRegressionThreshold.min, "minimum increase in elapsed time of a regression")
+		flags.IntVar(&opts.regression.percent, "regression-threshold", defaultRegressionThreshold.percent, "minimum %age increase in elapsed time of a regression")
 		flags.BoolVar(&opts.s, "s", false, "summary only")
 		flags.BoolVar(&opts.summary, "summary", false, "")
+		flags.IntVar(&opts.slowest, "slowest", 0, "number of slowest tests and packages to report")
+		flags.IntVar(&opts.snippets, "snippet-lines", 0, "lines of source code to show around source references")
+		flags.StringVar(&opts.sourceURL, "source-url", "", "URL template for links to source references")
 		flags.StringVar(&opts.t, "t", "", "report title")
 		flags.StringVar(&opts.title, "title", "", "")
 		flags.BoolVar(&opts.v, "v", false, "verbose output")
 		flags.BoolVar(&opts.verbose, "verbose", false, "")
-		if err := ParseFlags(flags, os.Args[1:]); err != nil {
+		flags.IntVar(&opts.yellow, "yellow-threshold", defaultThresholds.yellow, "pass rate %age for a yellow report icon")
+		if err := ParseFlags(flags, args); err != nil {
 			return nil, err
 		}
+		flags.Visit(func(f *flag.Flag) { opts.isSet[f.Name] = true })
+		if flags.NArg() > 0 {
+			opts.inputs = flags.Args()
+		}
+	}
+
+	if opts.h || opts.help {
+		return showUsage{}, nil
+	}
+
+	cf := coalesce(opts.c, opts.config)
+	cfg, err := loadConfig(coalesce(cf, defaultConfigFilename), cf != "")
+	if err != nil {
+		return nil, err
+	}
+
+	th := defaultThresholds
+	cfg.applyThresholds(&th)
+	if opts.isSet["orange-threshold"] {
+		th.orange = opts.orange
+	}
+	if opts.isSet["yellow-threshold"] {
+		th.yellow = opts.yellow
+	}
+	if err := th.validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.policy.validate(); err != nil {
+		return nil, err
+	}
+
+	sl := cfg.SnippetLines
+	if opts.isSet["snippet-lines"] {
+		sl = opts.snippets
+	}
+	co := cfg.Collapse
+	if opts.isSet["collapse"] {
+		co = opts.collapse
+	}
+	ml := cfg.MaxLines
+	if opts.isSet["max-lines"] {
+		ml = opts.maxLines
+	}
+	if ml < 0 {
+		return nil, fmt.Errorf("%w: max lines %d (must be 0 or more)", ErrInvalidOption, ml)
+	}
+	ms := cfg.MaxSize
+	if opts.isSet["max-size"] {
+		ms = opts.maxSize
 	}
-	of := coalesce(opts.o, opts.output, "test-report.md")
+
+	hr := cfg.HistoryRuns
+	if opts.isSet["history-runs"] {
+		hr = opts.runs
+	}
+	if hr < 0 {
+		return nil, fmt.Errorf("%w: history runs %d (must be 0 or more)", ErrInvalidOption, hr)
+	}
+
+	rg, err := cfg.regressionThreshold()
+	if err != nil {
+		return nil, err
+	}
+	if opts.isSet["regression-threshold"] {
+		rg.percent = opts.regression.percent
+	}
+	if opts.isSet["regression-min"] {
+		rg.min = opts.regression.min
+	}
+	if rg.percent < 0 {
+		return nil, fmt.Errorf("%w: regression threshold %d%% (must be 0 or more)", ErrInvalidOption, rg.percent)
+	}
+	if rg.min < 0 {
+		return nil, fmt.Errorf("%w: regression minimum %s (must be 0 or more)", ErrInvalidOption, rg.min)
+	}
+
+	sn := cfg.Slowest
+	if opts.isSet["slowest"] {
+		sn = opts.slowest
+	}
+	db, err := cfg.durationBudget()
+	if err != nil {
+		return nil, err
+	}
+	if opts.isSet["duration-budget"] {
+		db = opts.budget
+	}
+	if sn < 0 {
+		return nil, fmt.Errorf("%w: slowest %d (must be 0 or more)", ErrInvalidOption, sn)
+	}
+	if db < 0 {
+		return nil, fmt.Errorf("%w: duration budget %s (must be 0 or more)", ErrInvalidOption, db)
+	}
+
+	rf := rfMarkdown
+	if opts.format != "" {
+		var ok bool
+		if rf, ok = reportFormats[opts.format]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, opts.format)
+		}
+	}
+	of := coalesce(opts.o, opts.output, rf.defaultFilename())
 	rt := coalesce(opts.t, opts.title, "Test Report")
 
 	rm := rmFailedTests
@@ -58,16 +219,36 @@ func (o *Options) Parse() (interface{ Run(*Options) int }, error) {
 		rm = rmSummaryOnly
 	}
 
+	cmd := generateReport{
+		filename:     of,
+		title:        rt,
+		mode:         rm,
+		format:       rf,
+		thresholds:   th,
+		exitPolicy:   opts.policy,
+		sourceURL:    coalesce(opts.sourceURL, cfg.SourceURL),
+		moduleRoot:   coalesce(opts.moduleRoot, cfg.ModuleRoot),
+		snippetLines: sl,
+		coverProfile: opts.cover,
+		collapse:     co,
+		maxLines:     ml,
+		maxSize:      ms,
+		github:       opts.github,
+		baseline:     opts.baseline,
+		regression:   rg,
+		history:      coalesce(opts.history, cfg.History),
+		historyRuns:  hr,
+		slowest:      sn,
+		budget:       db,
+		inputs:       opts.inputs,
+		parser:       &parser{verbose: opts.v || opts.verbose, allowFlaky: opts.allowFlaky},
+	}
+
 	switch {
-	case opts.h || opts.help:
-		return showUsage{}, nil
+	case merge:
+		return mergeReports{cmd}, nil
 
 	default:
-		return generateReport{
-			filename: of,
-			title:    rt,
-			mode:     rm,
-			parser:   &parser{verbose: opts.v || opts.verbose},
-		}, nil
+		return cmd, nil
 	}
 }
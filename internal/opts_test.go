@@ -3,8 +3,11 @@ package internal
 import (
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/blugnu/test"
 )
@@ -35,6 +38,424 @@ func TestOpts(t *testing.T) {
 				test.That(t, result).IsNil()
 			},
 		},
+		{scenario: "parse/invalid format",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Args, []string{"test-report", "-format", "invalid"})()
+
+				opts := &Options{}
+
+				// ACT
+				result, err := opts.Parse()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidFormat)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "parse/invalid thresholds",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Args, []string{"test-report", "-orange-threshold", "96"})()
+
+				opts := &Options{}
+
+				// ACT
+				result, err := opts.Parse()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidThresholds)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "parse/invalid exit policy",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Args, []string{"test-report", "-min-pass-rate", "101"})()
+
+				opts := &Options{}
+
+				// ACT
+				result, err := opts.Parse()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidExitPolicy)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "parse/config file not found",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Args, []string{"test-report", "-c", filepath.Join(t.TempDir(), "missing.json")})()
+
+				opts := &Options{}
+
+				// ACT
+				result, err := opts.Parse()
+
+				// ASSERT
+				test.Error(t, err).Is(fs.ErrNotExist)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "parse/invalid config file",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Args, []string{"test-report"})()
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte(`{"thresholds": "invalid"}`), nil
+				})()
+
+				opts := &Options{}
+
+				// ACT
+				result, err := opts.Parse()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfig)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "parse/invalid config file duration budget",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Args, []string{"test-report"})()
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte(`{"durationBudget": "2 seconds"}`), nil
+				})()
+
+				opts := &Options{}
+
+				// ACT
+				result, err := opts.Parse()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfig)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "parse/invalid config file regression min",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Args, []string{"test-report"})()
+				defer test.Using(&osReadFile, func(string) ([]byte, error) {
+					return []byte(`{"regression": {"min": "1 second"}}`), nil
+				})()
+
+				opts := &Options{}
+
+				// ACT
+				result, err := opts.Parse()
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidConfig)
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "parse/config file regression",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args   []string
+					config string
+					result regressionThreshold
+				}{
+					{args: []string{}, config: `{}`, result: defaultRegressionThreshold},
+					{args: []string{}, config: `{"regression": {"threshold": 20}}`, result: regressionThreshold{percent: 20, min: time.Second}},
+					{args: []string{}, config: `{"regression": {"threshold": 20, "min": "500ms"}}`, result: regressionThreshold{percent: 20, min: 500 * time.Millisecond}},
+					{args: []string{"-regression-threshold", "10"}, config: `{"regression": {"threshold": 20, "min": "500ms"}}`, result: regressionThreshold{percent: 10, min: 500 * time.Millisecond}},
+					{args: []string{"-regression-min", "2s"}, config: `{"regression": {"threshold": 20, "min": "500ms"}}`, result: regressionThreshold{percent: 20, min: 2 * time.Second}},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s %s", tc.args, tc.config), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(tc.config), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, result.(generateReport).regression).Equals(tc.result)
+					})
+				}
+			},
+		},
+		{scenario: "parse/help with invalid config",
+			exec: func(t *testing.T) {
+				for _, args := range [][]string{{"-h"}, {"-help"}, {"-h", "-c", "missing.json"}} {
+					t.Run(fmt.Sprintf("%s", args), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, args...))()
+						defer test.Using(&osReadFile, func(filename string) ([]byte, error) {
+							if filename == "missing.json" {
+								return nil, fs.ErrNotExist
+							}
+							return []byte(`{"thresholds": {"orange": 101}}`), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, result).Equals(showUsage{})
+					})
+				}
+			},
+		},
+		{scenario: "parse/invalid max lines",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args   []string
+					config string
+				}{
+					{args: []string{"-max-lines", "-1"}, config: `{}`},
+					{args: []string{}, config: `{"maxLines": -1}`},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s %s", tc.args, tc.config), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(tc.config), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidOption)
+						test.That(t, result).IsNil()
+					})
+				}
+			},
+		},
+		{scenario: "parse/invalid history runs",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args   []string
+					config string
+				}{
+					{args: []string{"-history-runs", "-1"}, config: `{}`},
+					{args: []string{}, config: `{"historyRuns": -1}`},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s %s", tc.args, tc.config), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(tc.config), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidOption)
+						test.That(t, result).IsNil()
+					})
+				}
+			},
+		},
+		{scenario: "parse/invalid regression threshold",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args   []string
+					config string
+				}{
+					{args: []string{"-regression-threshold", "-1"}, config: `{}`},
+					{args: []string{"-regression-min", "-1s"}, config: `{}`},
+					{args: []string{}, config: `{"regression": {"threshold": -10}}`},
+					{args: []string{}, config: `{"regression": {"min": "-500ms"}}`},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s %s", tc.args, tc.config), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(tc.config), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidOption)
+						test.That(t, result).IsNil()
+					})
+				}
+			},
+		},
+		{scenario: "parse/invalid slowest",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args   []string
+					config string
+				}{
+					{args: []string{"-slowest", "-1"}, config: `{}`},
+					{args: []string{"-duration-budget", "-1ms"}, config: `{}`},
+					{args: []string{"-slowest", "-1", "-duration-budget", "1ms"}, config: `{}`},
+					{args: []string{}, config: `{"slowest": -1}`},
+					{args: []string{}, config: `{"durationBudget": "-2s"}`},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s %s", tc.args, tc.config), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(tc.config), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).Is(ErrInvalidOption)
+						test.That(t, result).IsNil()
+					})
+				}
+			},
+		},
+		{scenario: "parse/config file slowest",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args    []string
+					slowest int
+					budget  time.Duration
+				}{
+					{args: []string{}, slowest: 5, budget: 2 * time.Second},
+					{args: []string{"-slowest", "0", "-duration-budget", "500ms"}, slowest: 0, budget: 500 * time.Millisecond},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s", tc.args), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(`{"slowest": 5, "durationBudget": "2s"}`), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, result.(generateReport).slowest).Equals(tc.slowest)
+						test.That(t, result.(generateReport).budget).Equals(tc.budget)
+					})
+				}
+			},
+		},
+		{scenario: "parse/config file thresholds",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args   []string
+					result thresholds
+				}{
+					{args: []string{}, result: thresholds{orange: 60, yellow: 80}},
+					{args: []string{"-yellow-threshold", "90"}, result: thresholds{orange: 60, yellow: 90}},
+					{args: []string{"-config", "custom.json", "-orange-threshold", "0"}, result: thresholds{orange: 0, yellow: 80}},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s", tc.args), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(`{"thresholds": {"orange": 60, "yellow": 80}}`), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, result.(generateReport).thresholds).Equals(tc.result)
+					})
+				}
+			},
+		},
+		{scenario: "parse/config file output",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args     []string
+					collapse bool
+					maxLines int
+					maxSize  int
+				}{
+					{args: []string{}, collapse: true, maxLines: 50, maxSize: 1000},
+					{args: []string{"-collapse=false", "-max-lines", "0", "-max-size", "-1"}, collapse: false, maxLines: 0, maxSize: -1},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s", tc.args), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(`{"collapse": true, "maxLines": 50, "maxSize": 1000}`), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, result.(generateReport).collapse).Equals(tc.collapse)
+						test.That(t, result.(generateReport).maxLines).Equals(tc.maxLines)
+						test.That(t, result.(generateReport).maxSize).Equals(tc.maxSize)
+					})
+				}
+			},
+		},
+		{scenario: "parse/config file history",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					args        []string
+					history     string
+					historyRuns int
+				}{
+					{args: []string{}, history: "history.jsonl", historyRuns: 5},
+					{args: []string{"-history", "other.jsonl", "-history-runs", "30"}, history: "other.jsonl", historyRuns: 30},
+				}
+				for _, tc := range testcases {
+					t.Run(fmt.Sprintf("%s", tc.args), func(t *testing.T) {
+						// ARRANGE
+						defer test.Using(&os.Args, append([]string{"test-report"}, tc.args...))()
+						defer test.Using(&osReadFile, func(string) ([]byte, error) {
+							return []byte(`{"history": "history.jsonl", "historyRuns": 5}`), nil
+						})()
+
+						sut := &Options{}
+
+						// ACT
+						result, err := sut.Parse()
+
+						// ASSERT
+						test.Error(t, err).IsNil()
+						test.That(t, result.(generateReport).history).Equals(tc.history)
+						test.That(t, result.(generateReport).historyRuns).Equals(tc.historyRuns)
+					})
+				}
+			},
+		},
 		{scenario: "parse",
 			exec: func(t *testing.T) {
 				testcases := []struct {
@@ -46,90 +467,332 @@ func TestOpts(t *testing.T) {
 					{args: []string{"-help"}, result: showUsage{}},
 					{args: []string{},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "Test Report",
-							mode:     rmFailedTests,
-							parser:   &parser{},
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 					{args: []string{"-o", "report.md"},
 						result: generateReport{
-							filename: "report.md",
-							title:    "Test Report",
-							mode:     rmFailedTests,
-							parser:   &parser{},
+							filename:   "report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 					{args: []string{"-output", "report.md"},
 						result: generateReport{
-							filename: "report.md",
-							title:    "Test Report",
-							mode:     rmFailedTests,
-							parser:   &parser{},
+							filename:   "report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 					{args: []string{"-t", "My Title"},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "My Title",
-							mode:     rmFailedTests,
-							parser:   &parser{},
+							filename:   "test-report.md",
+							title:      "My Title",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 					{args: []string{"-title", "My Title"},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "My Title",
-							mode:     rmFailedTests,
-							parser:   &parser{},
+							filename:   "test-report.md",
+							title:      "My Title",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 					{args: []string{"-f"},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "Test Report",
-							mode:     rmAllTests,
-							parser:   &parser{},
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmAllTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 					{args: []string{"-full"},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "Test Report",
-							mode:     rmAllTests,
-							parser:   &parser{},
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmAllTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 					{args: []string{"-s"},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "Test Report",
-							mode:     rmSummaryOnly,
-							parser:   &parser{},
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmSummaryOnly,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 					{args: []string{"-summary"},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "Test Report",
-							mode:     rmSummaryOnly,
-							parser:   &parser{},
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmSummaryOnly,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-format", "md"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							format:     rfMarkdown,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-format", "junit"},
+						result: generateReport{
+							filename:   "test-report.xml",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							format:     rfJUnit,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-format", "junit", "-o", "junit.xml"},
+						result: generateReport{
+							filename:   "junit.xml",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							format:     rfJUnit,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-format", "html"},
+						result: generateReport{
+							filename:   "test-report.html",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							format:     rfHTML,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-format", "json"},
+						result: generateReport{
+							filename:   "test-report.json",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							format:     rfJSON,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"unit.json", "integration-*.json"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							inputs:     []string{"unit.json", "integration-*.json"},
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-o", "report.md", "unit.json"},
+						result: generateReport{
+							filename:   "report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							inputs:     []string{"unit.json"},
+							parser:     &parser{},
 						},
 					},
+					{args: []string{"merge", "shard-*.json"},
+						result: mergeReports{generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							inputs:     []string{"shard-*.json"},
+							parser:     &parser{},
+						}},
+					},
+					{args: []string{"merge"},
+						result: mergeReports{generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
+						}},
+					},
 					{args: []string{"-v"},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "Test Report",
-							mode:     rmFailedTests,
-							parser:   &parser{verbose: true},
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{verbose: true},
 						},
 					},
 					{args: []string{"--verbose"},
 						result: generateReport{
-							filename: "test-report.md",
-							title:    "Test Report",
-							mode:     rmFailedTests,
-							parser:   &parser{verbose: true},
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							parser:     &parser{verbose: true},
+						},
+					},
+					{args: []string{"-min-pass-rate", "90", "-max-failed", "-1", "-fail-on-skip", "-fail-on-no-tests", "-fail-on-build-failure", "-fail-on-regression", "-fail-on-flaky"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							exitPolicy: exitPolicy{
+								minPassRate:        90,
+								maxFailed:          -1,
+								failOnSkip:         true,
+								failOnNoTests:      true,
+								failOnBuildFailure: true,
+								failOnRegression:   true,
+								failOnFlaky:        true,
+							},
+							parser: &parser{},
+						},
+					},
+					{args: []string{"-source-url", "https://example.com/{path}#{line}", "-module-root", "module"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							sourceURL:  "https://example.com/{path}#{line}",
+							moduleRoot: "module",
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-snippet-lines", "3"},
+						result: generateReport{
+							filename:     "test-report.md",
+							title:        "Test Report",
+							mode:         rmFailedTests,
+							thresholds:   defaultThresholds,
+							regression:   defaultRegressionThreshold,
+							snippetLines: 3,
+							parser:       &parser{},
+						},
+					},
+					{args: []string{"-collapse", "-max-lines", "20", "-max-size", "65536"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							collapse:   true,
+							maxLines:   20,
+							maxSize:    65536,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-baseline", "main.json"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							baseline:   "main.json",
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-history", "history.jsonl", "-history-runs", "20"},
+						result: generateReport{
+							filename:    "test-report.md",
+							title:       "Test Report",
+							mode:        rmFailedTests,
+							thresholds:  defaultThresholds,
+							regression:  defaultRegressionThreshold,
+							history:     "history.jsonl",
+							historyRuns: 20,
+							parser:      &parser{},
+						},
+					},
+					{args: []string{"-slowest", "10", "-duration-budget", "1s"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							slowest:    10,
+							budget:     time.Second,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-github"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: defaultThresholds,
+							regression: defaultRegressionThreshold,
+							github:     true,
+							parser:     &parser{},
+						},
+					},
+					{args: []string{"-coverprofile", "cover.out"},
+						result: generateReport{
+							filename:     "test-report.md",
+							title:        "Test Report",
+							mode:         rmFailedTests,
+							thresholds:   defaultThresholds,
+							regression:   defaultRegressionThreshold,
+							coverProfile: "cover.out",
+							parser:       &parser{},
+						},
+					},
+					{args: []string{"-orange-threshold", "50", "-yellow-threshold", "75"},
+						result: generateReport{
+							filename:   "test-report.md",
+							title:      "Test Report",
+							mode:       rmFailedTests,
+							thresholds: thresholds{orange: 50, yellow: 75},
+							regression: defaultRegressionThreshold,
+							parser:     &parser{},
 						},
 					},
 				}
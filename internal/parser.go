@@ -2,41 +2,140 @@ package internal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type line struct {
-	Time    string   `json:"Time,omitempty"`
-	Action  string   `json:"Action"`
-	Package string   `json:"Package,omitempty"`
-	Test    *string  `json:"Test,omitempty"`
-	Elapsed *float64 `json:"Elapsed,omitempty"`
-	Output  *string  `json:"Output,omitempty"`
+	Time        string   `json:"Time,omitempty"`
+	Action      string   `json:"Action"`
+	Package     string   `json:"Package,omitempty"`
+	ImportPath  string   `json:"ImportPath,omitempty"`
+	Test        *string  `json:"Test,omitempty"`
+	Elapsed     *float64 `json:"Elapsed,omitempty"`
+	Output      *string  `json:"Output,omitempty"`
+	FailedBuild string   `json:"FailedBuild,omitempty"`
 }
 
 func (line line) elapsedDur() time.Duration {
 	return time.Duration(math.Round(*line.Elapsed*1000)) * time.Millisecond
 }
 
+// timestamp returns the time of the line, or the zero time if the line has
+// no (valid) time.
+func (line line) timestamp() time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, line.Time)
+	return t
+}
+
+// frames are the prefixes of output lines emitted by the test runner to
+// identify the start, pausing, continuation and result of a test.  These
+// are not recorded as test output; test results are recorded from the
+// pass/fail/skip actions, since the "--- PASS/FAIL/SKIP" output of a test
+// running in parallel may not be attributed to that test.
+var frames = []string{
+	"=== RUN",
+	"=== PAUSE",
+	"=== CONT",
+	"=== NAME",
+	"--- PASS",
+	"--- FAIL",
+	"--- SKIP",
+}
+
 type parser struct {
-	pkgs    map[string]*packageinfo
-	tests   map[string]map[string]*testinfo
-	srcref  *regexp.Regexp
-	verbose bool
+	pkgs       map[string]*packageinfo
+	tests      map[string]map[string]*testinfo
+	benches    map[string]map[string]*benchmark
+	benchout   map[*benchmark]string
+	builds     map[string][]string
+	panics     map[string][]string
+	started    time.Time
+	ended      time.Time
+	srcref     *regexp.Regexp
+	benchres   *regexp.Regexp
+	frame      *regexp.Regexp
+	running    *regexp.Regexp
+	coverage   *regexp.Regexp
+	verbose    bool
+	allowFlaky bool
 }
 
+// parse parses go test -json output into a testrun.  An error is returned
+// if the input is not valid go test -json output.
 func (p *parser) parse(r io.Reader, rpt *testrun) error {
+	p.reset(rpt)
+	if err := p.decode(r, rpt); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	p.process(rpt)
+
+	return nil
+}
+
+// parseFiles parses the go test -json output in the specified files into a
+// single testrun.  Files are parsed in order, as if the output in each file
+// followed the output in the file before it (a package appearing in more
+// than one file is a re-run of that package), but each file is decoded
+// separately; an error identifying the file is returned if any file is not
+// valid go test -json output.
+func (p *parser) parseFiles(filenames []string, rpt *testrun) error {
+	p.reset(rpt)
+	for _, filename := range filenames {
+		if err := p.parseFile(filename, rpt); err != nil {
+			return err
+		}
+	}
+	p.process(rpt)
+
+	return nil
+}
+
+// parseFile decodes the go test -json output in the specified file into
+// a testrun.
+func (p *parser) parseFile(filename string, rpt *testrun) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := p.decode(file, rpt); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidInput, filename, err)
+	}
+	return nil
+}
+
+// reset initialises the parser and the testrun to be parsed.
+func (p *parser) reset(rpt *testrun) {
 	p.pkgs = map[string]*packageinfo{}
 	p.tests = map[string]map[string]*testinfo{}
+	p.benches = map[string]map[string]*benchmark{}
+	p.benchout = map[*benchmark]string{}
+	p.builds = map[string][]string{}
+	p.panics = map[string][]string{}
+	p.started = time.Time{}
+	p.ended = time.Time{}
 	p.srcref, _ = regexp.Compile(`(.*\.go:[0-9]*): (.*)\n`)
+	p.benchres, _ = regexp.Compile(`^Benchmark\S*\s+([0-9]+)\s+(.*)$`)
+	p.frame, _ = regexp.Compile(`^\s+(.*):([0-9]+)(?: \+0x[0-9a-f]+)?$`)
+	p.running, _ = regexp.Compile(`^\t\t(\S+) \(.*\)$`)
+	p.coverage, _ = regexp.Compile(`coverage: ([0-9.]+)% of statements`)
 
 	*rpt = testrun{}
+}
+
+// decode records each line of go test -json output read from r, until the
+// end of the input.  An error is returned if any line cannot be decoded.
+func (p *parser) decode(r io.Reader, rpt *testrun) error {
 	echo := func([]byte) (int, error) { return 0, nil }
 	if p.verbose {
 		echo = os.Stdout.Write
@@ -45,40 +144,84 @@ func (p *parser) parse(r io.Reader, rpt *testrun) error {
 	decoder := json.NewDecoder(r)
 	for {
 		l := &line{}
-		if err := decoder.Decode(l); err != nil {
-			break
+		if err := decoder.Decode(l); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
 		}
 
 		s, _ := json.Marshal(l)
 		_, _ = echo(s)
 
-		if l.Test == nil && l.Elapsed != nil {
-			rpt.elapsed = l.elapsedDur()
+		if ts := l.timestamp(); !ts.IsZero() {
+			if p.started.IsZero() {
+				p.started = ts
+			}
+			p.ended = ts
 		}
 
 		if fn, ok := map[string]func(*line, *testrun){
-			"start":  p.addPackage,
-			"run":    p.addTest,
-			"output": p.recordOutput,
-			"pass":   p.recordPass,
-			"fail":   p.recordFailure,
-			"skip":   p.recordSkip,
+			"build-output": p.recordBuildOutput,
+			"start":        p.addPackage,
+			"run":          p.addTest,
+			"output":       p.recordOutput,
+			"pause":        p.recordPause,
+			"cont":         p.recordContinue,
+			"pass":         p.recordPass,
+			"fail":         p.recordFailure,
+			"skip":         p.recordSkip,
 		}[l.Action]; ok {
 			fn(l, rpt)
 		}
 	}
+}
+
+// process completes the testrun once all input has been decoded.
+func (p *parser) process(rpt *testrun) {
 	p.processOutput()
+	p.processPanics(rpt)
+	p.processPackageFailures(rpt)
+	p.processRaces(rpt)
+	p.processBenchmarks(rpt)
+	p.processAttempts(rpt)
+	p.processElapsed(rpt)
+	rpt.recount()
+}
 
-	if rpt.numTests > 0 {
-		rpt.percentPassed = (rpt.numPassed * 100) / rpt.numTests
+// recordBuildOutput records the output of a build, keyed by the import path
+// of the package being built.  The header line identifying the package (e.g.
+// "# <import path>") is not recorded.
+//
+// If the build fails, the output is subsequently associated with the package
+// by recordFailure, identified by the FailedBuild field of the package "fail"
+// action.
+func (p *parser) recordBuildOutput(line *line, rpt *testrun) {
+	if line.Output == nil || strings.HasPrefix(*line.Output, "# ") {
+		return
 	}
-
-	return nil
+	p.builds[line.ImportPath] = append(p.builds[line.ImportPath], strings.TrimSuffix(*line.Output, "\n"))
 }
 
 // addPackage adds a package to the testrun using the package name from
 // the line, setting the initial state of the package passed flag to true.
+//
+// If the package has already been started (e.g. a re-run of failed tests
+// in the same input) the existing package is reset to run again, retaining
+// its tests (so that each test run again records another attempt; see
+// addTest) and output.  Any panic in the previous run is attributed to the
+// tests of that run (see attributePanic); the result of the package is that
+// of the final run.
 func (p *parser) addPackage(line *line, rpt *testrun) {
+	if pi, ok := p.pkgs[line.Package]; ok {
+		p.attributePanic(line.Package)
+		pi.passed = true
+		pi.failed = false
+		pi.buildFailed = false
+		pi.buildOutput = nil
+		pi.panic = nil
+		return
+	}
+
 	pi := &packageinfo{
 		name:   line.Package,
 		passed: true,
@@ -87,80 +230,236 @@ func (p *parser) addPackage(line *line, rpt *testrun) {
 	rpt.packages = append(rpt.packages, pi)
 	p.pkgs[line.Package] = pi
 	p.tests[line.Package] = map[string]*testinfo{}
+	p.benches[line.Package] = map[string]*benchmark{}
 }
 
 // addTest adds a test to the testrun using the package name and test name
 // from the line, setting the initial state of the test result to failed.
+//
+// If the test has already been run (e.g. go test -count, or a re-run of
+// failed tests in the same input) the result of the previous attempt is
+// recorded and the test is reset to run again; output from all attempts is
+// recorded.
+//
+// Benchmarks are also "run" and are added as benchmarks rather than tests.
 func (p *parser) addTest(line *line, rpt *testrun) {
+	if strings.HasPrefix(*line.Test, "Benchmark") {
+		p.addBenchmark(line)
+		return
+	}
+
+	if ti := p.test(line); ti != nil {
+		ti.attempts = append(ti.attempts, ti.result)
+		ti.result = trFailed
+		ti.started = line.timestamp()
+		ti.ended = time.Time{}
+		ti.paused = false
+		return
+	}
+
 	ti := &testinfo{
 		path:        *line.Test,
 		output:      map[string][]string{},
 		packageName: line.Package,
+		started:     line.timestamp(),
 	}
 	p.tests[line.Package][*line.Test] = ti
 	pkg := p.pkgs[line.Package]
 	pkg.tests = append(pkg.tests, ti)
-	rpt.numTests++
+}
+
+// addBenchmark adds a benchmark to the package identified by the line.
+func (p *parser) addBenchmark(line *line) {
+	b := &benchmark{name: *line.Test}
+	p.benches[line.Package][*line.Test] = b
+	pkg := p.pkgs[line.Package]
+	pkg.benchmarks = append(pkg.benchmarks, b)
+}
+
+// benchmark returns the benchmark identified by the line, or nil if the line
+// does not relate to a benchmark.
+func (p *parser) benchmark(line *line) *benchmark {
+	if line.Test == nil {
+		return nil
+	}
+	return p.benches[line.Package][*line.Test]
+}
+
+// recordPause records that a test has paused, to wait to run in parallel
+// with other tests.
+func (p *parser) recordPause(line *line, rpt *testrun) {
+	if test := p.test(line); test != nil {
+		test.paused = true
+		test.parallel = true
+	}
+}
+
+// recordContinue records that a paused test has continued.
+func (p *parser) recordContinue(line *line, rpt *testrun) {
+	if test := p.test(line); test != nil {
+		test.paused = false
+	}
+}
+
+// test returns the test identified by the line, or nil if the line does not
+// relate to a test (or relates to a benchmark).
+func (p *parser) test(line *line) *testinfo {
+	if line.Test == nil {
+		return nil
+	}
+	return p.tests[line.Package][*line.Test]
+}
+
+// packageResults are the prefixes of package output lines emitted by the
+// test runner to report the result of a package.  These are not recorded
+// as package output.
+var packageResults = []string{
+	"PASS\n",
+	"FAIL\n",
+	"ok  \t",
+	"FAIL\t",
+	"?   \t",
+	"exit status ",
+}
+
+// recordPackageOutput records package output, i.e. output not associated
+// with any test (e.g. output from TestMain or an init() function).  Output
+// from a panic (see recordPackagePanic), the coverage of the package (e.g.
+// "coverage: 75.0% of statements") and output reporting the result of the
+// package is not recorded.
+func (p *parser) recordPackageOutput(line *line) {
+	if line.Output == nil || p.pkgs[line.Package] == nil || p.recordPackagePanic(line) {
+		return
+	}
+	if m := p.coverage.FindStringSubmatch(*line.Output); m != nil {
+		if pct, err := strconv.ParseFloat(m[1], 64); err == nil {
+			p.pkgs[line.Package].coverage = &pct
+		}
+		return
+	}
+	if strings.HasPrefix(*line.Output, "coverage: ") {
+		return
+	}
+	for _, result := range packageResults {
+		if strings.HasPrefix(*line.Output, result) {
+			return
+		}
+	}
+	pkg := p.pkgs[line.Package]
+	pkg.output = append(pkg.output, strings.TrimSuffix(*line.Output, "\n"))
+}
+
+// recordPackagePanic records package output from a panic or test timeout,
+// returning true if the output is part of a panic.  Output is recorded from
+// the initial "panic: " line up to the result of the package ("FAIL").
+func (p *parser) recordPackagePanic(line *line) bool {
+	out := *line.Output
+	switch {
+	case strings.HasPrefix(out, "panic: "):
+		p.panics[line.Package] = []string{out}
+	case len(p.panics[line.Package]) == 0:
+		return false
+	case strings.HasPrefix(out, "FAIL"), strings.HasPrefix(out, "exit status"):
+		return false
+	default:
+		p.panics[line.Package] = append(p.panics[line.Package], out)
+	}
+	return true
 }
 
 // recordOutput records the output of a test, adding it to the testinfo output
-// map "raw" item.  If the output is a test result, the test result is updated
-// and the output is not recorded.
+// map "raw" item.  Output identifying the start, pausing, continuation or
+// result of a test is not recorded.
+//
+// The output of a benchmark is recorded separately, to be parsed for the
+// benchmark results once all output has been recorded (the results of a
+// benchmark may be split over more than one line of output).
 func (p *parser) recordOutput(line *line, rpt *testrun) {
-	if line.Test == nil || strings.HasPrefix(*line.Output, "=== RUN") {
+	if line.Test == nil {
+		p.recordPackageOutput(line)
 		return
 	}
+	for _, frame := range frames {
+		if strings.HasPrefix(*line.Output, frame) {
+			return
+		}
+	}
 
-	test := p.tests[line.Package][*line.Test]
-	if strings.HasPrefix(*line.Output, "--- FAIL") {
-		test.result = trFailed
-		//FUTURE: extract the elapsed time from the output (formatted in the output string)
+	if b := p.benchmark(line); b != nil {
+		p.benchout[b] += *line.Output
 		return
 	}
-	if strings.HasPrefix(*line.Output, "--- PASS") {
-		test.result = trPassed
-		//FUTURE: extract the elapsed time from the output (formatted in the output string)
-		return
+
+	if test := p.test(line); test != nil {
+		test.output["raw"] = append(test.output["raw"], *line.Output)
 	}
+}
 
-	test.output["raw"] = append(test.output["raw"], *line.Output)
+// endTest records the result of a test, with the end time and elapsed time
+// of the test from the line (if recorded).
+func (p *parser) endTest(test *testinfo, line *line, result testResult) {
+	test.result = result
+	test.ended = line.timestamp()
+	if line.Elapsed != nil {
+		test.elapsed = line.elapsedDur()
+	}
 }
 
-// recordPass records a test pass, updating the test result and incrementing
-// the number of passed tests in the testrun.  If the line has an elapsed time
-// with no associated test, the elapsed time is updated in the package info.
+// recordPass records a test pass, updating the test result, end time and
+// elapsed time.  If the line has an elapsed time with no associated test,
+// the elapsed time is updated in the package info.
 func (p *parser) recordPass(line *line, rpt *testrun) {
 	switch {
+	case p.benchmark(line) != nil:
+		return
 	case line.Test != nil:
-		rpt.numPassed++
-		p.tests[line.Package][*line.Test].result = trPassed
+		p.endTest(p.tests[line.Package][*line.Test], line, trPassed)
 	case line.Elapsed != nil:
 		p.pkgs[line.Package].elapsed = line.elapsedDur()
 	}
 }
 
-// recordFailure records a test failure, updating the test result and incrementing
-// the number of failed tests in the testrun.  If the line has an elapsed time
-// with no associated test, the elapsed time is updated in the package info.
+// recordFailure records a test failure, updating the test result, end time
+// and elapsed time.  If the line has an elapsed time with no associated
+// test, the elapsed time is updated in the package info.
+//
+// If the line identifies a failed build, the package is marked as having
+// failed to build, with any output recorded for the build.  Otherwise, a
+// failed package is marked as failed (see processPackageFailures).
 func (p *parser) recordFailure(line *line, rpt *testrun) {
-	p.pkgs[line.Package].passed = false
+	pkg := p.pkgs[line.Package]
+	pkg.passed = false
+	if b := p.benchmark(line); b != nil {
+		b.failed = true
+		return
+	}
+	if test := p.test(line); test != nil {
+		p.endTest(test, line, trFailed)
+	}
 	switch {
-	case line.Test != nil:
-		rpt.numFailed++
-	case line.Elapsed != nil:
-		p.pkgs[line.Package].elapsed = line.elapsedDur()
+	case line.Test == nil && line.FailedBuild != "":
+		pkg.buildFailed = true
+		pkg.buildOutput = p.builds[line.FailedBuild]
+	case line.Test == nil:
+		pkg.failed = true
+		p.attributePanic(line.Package)
+	}
+	if line.Test == nil && line.Elapsed != nil {
+		pkg.elapsed = line.elapsedDur()
 	}
 }
 
-// recordSkip records a test skip, updating the test result and incrementing
-// the number of skipped tests in the testrun.
+// recordSkip records a test skip, updating the test result, end time and
+// elapsed time.
 func (p *parser) recordSkip(line *line, rpt *testrun) {
 	if line.Test == nil {
 		p.pkgs[line.Package].passed = false
 		return
 	}
-	p.tests[line.Package][*line.Test].result = trSkipped
-	rpt.numSkipped++
+	if p.benchmark(line) != nil {
+		return
+	}
+	p.endTest(p.tests[line.Package][*line.Test], line, trSkipped)
 }
 
 // processOutput calls processTestOutput for each test that has "raw" output.
@@ -175,6 +474,312 @@ func (p *parser) processOutput() {
 	}
 }
 
+// processBenchmarks parses the output of each benchmark for the benchmark
+// results.  Benchmarks with no results (e.g. a benchmark that only runs
+// sub-benchmarks, or a skipped benchmark) are removed unless they failed.
+//
+// A benchmark result is reported in output of the form:
+//
+//	BenchmarkName-8   	 1000000	      1234 ns/op	  16 B/op	   1 allocs/op
+//
+// i.e. the name of the benchmark, the number of iterations and a number of
+// tab-separated "<value> <unit>" metrics.
+func (p *parser) processBenchmarks(rpt *testrun) {
+	for _, pkg := range rpt.packages {
+		benchmarks := []*benchmark{}
+		for _, b := range pkg.benchmarks {
+			if p.parseBenchmark(b, p.benchout[b]) || b.failed {
+				benchmarks = append(benchmarks, b)
+			}
+		}
+		pkg.benchmarks = benchmarks
+	}
+}
+
+// parseBenchmark parses the results of a benchmark from its output, returning
+// true if results were found.
+func (p *parser) parseBenchmark(b *benchmark, output string) bool {
+	for _, s := range strings.Split(output, "\n") {
+		m := p.benchres.FindStringSubmatch(strings.TrimSpace(s))
+		if m == nil {
+			continue
+		}
+
+		b.iterations, _ = strconv.Atoi(m[1])
+		for _, metric := range strings.Split(m[2], "\t") {
+			f := strings.Fields(metric)
+			if len(f) != 2 {
+				continue
+			}
+			v, err := strconv.ParseFloat(f[0], 64)
+			if err != nil {
+				continue
+			}
+			switch f[1] {
+			case "ns/op":
+				b.nsPerOp = v
+			case "B/op":
+				b.bytesPerOp = &v
+			case "allocs/op":
+				b.allocsPerOp = &v
+			default:
+				if b.metrics == nil {
+					b.metrics = map[string]float64{}
+				}
+				b.metrics[f[1]] = v
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// attributePanic attributes any panic in the output of a package to the
+// tests that were running when the panic occurred: for a timeout, the tests
+// identified as running in the panic output, otherwise any tests that had
+// not ended (excluding any tests paused, waiting to run in parallel).  If no
+// tests are identified, the panic is attributed to the package.
+//
+// The panic is then cleared, so that the output of any re-run of the package
+// is not appended to it.
+func (p *parser) attributePanic(pkg string) {
+	output, ok := p.panics[pkg]
+	if !ok {
+		return
+	}
+	delete(p.panics, pkg)
+
+	pi := p.parsePanic(output, pkg)
+	tests := []*testinfo{}
+	for _, t := range p.pkgs[pkg].tests {
+		if t.panic == nil && ((pi.timeout && slices.Contains(pi.running, t.path)) || (!pi.timeout && t.ended.IsZero() && !t.paused)) {
+			tests = append(tests, t)
+		}
+	}
+	for _, t := range tests {
+		t.panic = pi
+	}
+	if len(tests) == 0 {
+		p.pkgs[pkg].panic = pi
+	}
+}
+
+// processPanics attributes any panic in package output not yet attributed
+// (e.g. if the output ended before the result of the package) and records
+// a test with a panic that did not end (e.g. a test that timed out) as a
+// failed test.
+func (p *parser) processPanics(rpt *testrun) {
+	for pkg := range p.panics {
+		p.attributePanic(pkg)
+	}
+
+	for _, pkg := range rpt.packages {
+		for _, t := range pkg.tests {
+			if t.panic != nil && t.ended.IsZero() {
+				t.result = trFailed
+			}
+		}
+	}
+}
+
+// processPackageFailures identifies the packages that failed without any
+// failed test (e.g. due to an error in TestMain).  A package with a failed
+// test is not a package failure.
+//
+// A package in which a test failed in any attempt but passed in its final
+// attempt (a flaky test) remains a package failure, since go test reported
+// the package as failed, unless flaky tests are allowed.
+func (p *parser) processPackageFailures(rpt *testrun) {
+	failed := func(t *testinfo) bool { return t.result == trFailed }
+	if p.allowFlaky {
+		failed = (*testinfo).failedAny
+	}
+	for _, pkg := range rpt.packages {
+		pkg.failed = pkg.failed && !slices.ContainsFunc(pkg.tests, failed)
+	}
+}
+
+// processElapsed records the elapsed time of the test run.  Packages are
+// tested concurrently, so this is the time between the first and last
+// timestamped events or, if longer (or the input has no timestamps), the
+// longest elapsed time of any package.
+func (p *parser) processElapsed(rpt *testrun) {
+	rpt.elapsed = p.ended.Sub(p.started).Round(time.Millisecond)
+	for _, pkg := range rpt.packages {
+		rpt.elapsed = max(rpt.elapsed, pkg.elapsed)
+	}
+}
+
+// processAttempts records the final result of each test that was run more
+// than once, completing the results of all attempts of the test.
+func (p *parser) processAttempts(rpt *testrun) {
+	for _, pkg := range rpt.packages {
+		for _, t := range pkg.tests {
+			if len(t.attempts) > 0 {
+				t.attempts = append(t.attempts, t.result)
+			}
+		}
+	}
+}
+
+// parsePanic parses the output of a panic in a package into a panicinfo.
+// The output is expected to be in the format:
+//
+//	|panic: <message>
+//	|	running tests:            // for a timeout
+//	|		<test> (<duration>)   // for a timeout
+//	|
+//	|goroutine <id> [<state>]:
+//	|<function>(<args>)
+//	|	<file>:<line> +<offset>
+//	|...
+//	|
+//	|goroutine <id> [<state>]:
+//	|...
+//
+// The stack recorded is that of the first goroutine with a frame in the
+// package; if there is no such goroutine, the stack of the first goroutine
+// is recorded.
+func (p *parser) parsePanic(output []string, pkg string) *panicinfo {
+	pi := &panicinfo{output: make([]string, 0, len(output))}
+	for _, s := range output {
+		pi.output = append(pi.output, strings.TrimSuffix(s, "\n"))
+	}
+	pi.message = strings.TrimPrefix(pi.output[0], "panic: ")
+	pi.timeout = strings.HasPrefix(pi.message, "test timed out after")
+
+	stacks := [][]stackframe{}
+	fn := ""
+	for _, s := range pi.output[1:] {
+		switch {
+		case strings.HasPrefix(s, "goroutine "):
+			stacks = append(stacks, []stackframe{})
+		case len(stacks) == 0:
+			if m := p.running.FindStringSubmatch(s); m != nil {
+				pi.running = append(pi.running, m[1])
+			}
+		case s == "":
+			continue
+		case !strings.HasPrefix(s, "\t"):
+			fn = stackFunction(s)
+		default:
+			if m := p.frame.FindStringSubmatch(s); m != nil {
+				n, _ := strconv.Atoi(m[2])
+				stacks[len(stacks)-1] = append(stacks[len(stacks)-1], stackframe{function: fn, file: m[1], line: n})
+			}
+		}
+	}
+
+	for _, stack := range stacks {
+		if slices.ContainsFunc(stack, func(f stackframe) bool { return strings.HasPrefix(f.function, pkg+".") }) {
+			pi.stack = stack
+			return pi
+		}
+	}
+	if len(stacks) > 0 {
+		pi.stack = stacks[0]
+	}
+	return pi
+}
+
+// raceSeparator is the line delimiting a data race report in output.
+const raceSeparator = "=================="
+
+// stackFunction returns the function identified by a line of a stack trace,
+// removing any arguments (e.g. "pkg.Func(0x1, ...)" is returned as "pkg.Func").
+func stackFunction(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "("); i > 0 && strings.HasSuffix(s, ")") {
+		return s[:i]
+	}
+	return s
+}
+
+// processRaces extracts any data race reports from the output of each
+// package (e.g. a race detected after all tests in the package completed).
+//
+// Races in the output of a test are extracted when the output of the test
+// is processed (see processTestOutput).
+func (p *parser) processRaces(rpt *testrun) {
+	for _, pkg := range rpt.packages {
+		pkg.output, pkg.races = p.extractRaces(pkg.output)
+	}
+}
+
+// extractRaces extracts data race reports from output, returning the
+// output with the reports removed, together with the races reported.  A
+// report is delimited by separator lines (see raceSeparator).  If the final
+// separator of a report is missing, the output of the report is retained.
+func (p *parser) extractRaces(output []string) ([]string, []*raceinfo) {
+	var (
+		result = make([]string, 0, len(output))
+		races  []*raceinfo
+		report []string
+	)
+	for _, s := range output {
+		switch {
+		case strings.TrimSpace(s) == raceSeparator && report == nil:
+			report = []string{s}
+		case strings.TrimSpace(s) == raceSeparator:
+			races = append(races, p.parseRace(report[1:]))
+			report = nil
+		case report != nil:
+			report = append(report, s)
+		default:
+			result = append(result, s)
+		}
+	}
+	return append(result, report...), races
+}
+
+// parseRace parses a data race report into a raceinfo.  The report is
+// expected to be in the format:
+//
+//	|WARNING: DATA RACE
+//	|Read at 0x00c0000182b8 by goroutine 8:
+//	|  <function>()
+//	|      <file>:<line> +<offset>
+//	|
+//	|Previous write at 0x00c0000182b8 by goroutine 7:
+//	|  ...
+//	|
+//	|Goroutine 8 (running) created at:
+//	|  ...
+//
+// i.e. the conflicting accesses followed by the sites at which the
+// goroutines involved were created.
+func (p *parser) parseRace(report []string) *raceinfo {
+	race := &raceinfo{}
+	var current *racestack
+	fn := ""
+	for _, s := range report {
+		s = strings.TrimSuffix(s, "\n")
+		switch {
+		case strings.TrimSpace(s) == "", strings.HasPrefix(s, "WARNING: DATA RACE"):
+			continue
+		case !strings.HasPrefix(s, " ") && strings.HasSuffix(s, ":"):
+			rs := racestack{description: strings.TrimSuffix(s, ":")}
+			if strings.HasPrefix(s, "Goroutine ") {
+				race.goroutines = append(race.goroutines, rs)
+				current = &race.goroutines[len(race.goroutines)-1]
+			} else {
+				race.accesses = append(race.accesses, rs)
+				current = &race.accesses[len(race.accesses)-1]
+			}
+		case current == nil:
+			continue
+		default:
+			if m := p.frame.FindStringSubmatch(s); m != nil {
+				n, _ := strconv.Atoi(m[2])
+				current.stack = append(current.stack, stackframe{function: fn, file: m[1], line: n})
+				continue
+			}
+			fn = stackFunction(s)
+		}
+	}
+	return race
+}
+
 // processTestOutput processes the raw output of a test, identifying
 // the output emitted from each source location and storing it in
 // the testinfo output map.
@@ -192,20 +797,27 @@ func (p *parser) processOutput() {
 // indentation introduced by the test runner (8 spaces for all lines apart
 // from the initial line with, source reference, presented in-line
 // with the source reference with no additional indentation).
+//
+// Output from a panic (or test timeout) is not stored in the output map but
+// parsed into a panicinfo for the test (see parsePanic).  Similarly, data
+// race reports are parsed into raceinfo (see extractRaces).
 func (p *parser) processTestOutput(test *testinfo) {
-	skiplog, _ := regexp.Compile(fmt.Sprintf(`: %s \([0-9]+.[0-9]+s\)`, test.path))
+	test.output["raw"], test.races = p.extractRaces(test.output["raw"])
+
 	ref := ""
-	for _, s := range test.output["raw"] {
+	for i, s := range test.output["raw"] {
+		if strings.HasPrefix(s, "panic: ") {
+			test.panic = p.parsePanic(test.output["raw"][i:], test.packageName)
+			break
+		}
 		if s := p.srcref.FindAllStringSubmatch(s, -1); len(s) > 0 {
 			ref = strings.TrimSpace(s[0][1])
 			test.output[ref] = append([]string{}, s[0][2])
 			continue
 		}
-		if test.result != trSkipped || !skiplog.MatchString(s) {
-			if strings.HasPrefix(s, "        ") {
-				s = s[8 : len(s)-1]
-			}
-			test.output[ref] = append(test.output[ref], s)
+		if strings.HasPrefix(s, "        ") {
+			s = s[8 : len(s)-1]
 		}
+		test.output[ref] = append(test.output[ref], s)
 	}
 }
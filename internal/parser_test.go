@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/blugnu/test"
 )
@@ -43,6 +48,14 @@ func generate() {
 	testPackages("packages.json", "./pkga", "./pkgb")
 	testPackages("no-test-files.json", "./no-test-files")
 	testPackages("no-code.json", "./no-code")
+	testPackages("build-failure.json", "./build-failure", "./pkga")
+	testPackages("benchmarks.json", "-bench", ".", "-benchtime", "10x", "./benchmarks")
+	testPackages("parallel.json", "-parallel", "16", "./parallel")
+	testPackages("panic.json", "./panic")
+	testPackages("timeout.json", "-timeout", "1s", "./timeout")
+	testPackages("testmain.json", "./testmain")
+	testPackages("race.json", "-race", "./race")
+	testPackages("flaky.json", "-count", "2", "./flaky")
 }
 
 func TestParse(t *testing.T) {
@@ -108,6 +121,179 @@ func TestParse(t *testing.T) {
 				test.That(t, report.numSkipped).Equals(0, "tests skipped")
 			},
 		},
+		{scenario: "no-code.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/no-code.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(report.packages)).Equals(1, "number of packages")
+				test.That(t, report.numTests).Equals(0, "number of tests")
+				test.That(t, report.numBuildFailed).Equals(1, "build failures")
+				test.IsTrue(t, report.packages[0].buildFailed, "package build failed")
+				test.Strings(t, report.packages[0].buildOutput).Contains("no Go files in")
+			},
+		},
+		{scenario: "build-failure.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/build-failure.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(report.packages)).Equals(2, "number of packages")
+				test.That(t, report.numTests).Equals(1, "number of tests")
+				test.That(t, report.numPassed).Equals(1, "tests passed")
+				test.That(t, report.numBuildFailed).Equals(1, "build failures")
+
+				pkg := report.packages[0]
+				test.IsTrue(t, pkg.buildFailed, "package build failed")
+				test.IsFalse(t, pkg.passed, "package passed")
+				test.Strings(t, pkg.buildOutput).Equals([]string{
+					"build-failure/build_failure_test.go:6:6: declared and not used: n",
+					`build-failure/build_failure_test.go:6:14: cannot use "not an int" (untyped string constant) as int value in variable declaration`,
+					"build-failure/build_failure_test.go:7:8: undefined: undefined",
+				})
+				test.IsFalse(t, report.packages[1].buildFailed, "package build failed")
+			},
+		},
+		{scenario: "benchmarks.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/benchmarks.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(report.packages)).Equals(1, "number of packages")
+				test.That(t, report.numTests).Equals(1, "number of tests")
+				test.That(t, report.numPassed).Equals(1, "tests passed")
+				test.That(t, report.numFailed).Equals(0, "tests failed")
+				test.That(t, report.numBenchmarks).Equals(4, "number of benchmarks")
+
+				names := []string{}
+				for _, b := range report.packages[0].benchmarks {
+					names = append(names, b.name)
+					test.That(t, b.iterations).Equals(10, b.name+" iterations")
+				}
+				test.Strings(t, names).Equals([]string{
+					"BenchmarkJoin",
+					"BenchmarkAlloc",
+					"BenchmarkMetric",
+					"BenchmarkSub/small",
+				})
+
+				alloc := report.packages[0].benchmarks[1]
+				test.IsTrue(t, alloc.bytesPerOp != nil, "B/op reported")
+				test.IsTrue(t, alloc.allocsPerOp != nil, "allocs/op reported")
+
+				metric := report.packages[0].benchmarks[2]
+				test.That(t, metric.metrics).Equals(map[string]float64{"widgets/op": 42})
+			},
+		},
+		{scenario: "parseBenchmark",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				p := parser{}
+				_ = p.parse(bytes.NewReader(nil), &testrun{})
+				b := &benchmark{}
+
+				// ACT
+				ok := p.parseBenchmark(b, "BenchmarkFoo\nBenchmarkFoo-8   \t 1000000\t      1234 ns/op\t  16.00 B/op\t   1 allocs/op\t  20.5 MB/s\n")
+
+				// ASSERT
+				test.IsTrue(t, ok, "results found")
+				test.That(t, b.iterations).Equals(1000000)
+				test.That(t, b.nsPerOp).Equals(1234)
+				test.That(t, *b.bytesPerOp).Equals(16)
+				test.That(t, *b.allocsPerOp).Equals(1)
+				test.That(t, b.metrics).Equals(map[string]float64{"MB/s": 20.5})
+			},
+		},
+		{scenario: "parallel.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/parallel.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(report.packages)).Equals(1, "number of packages")
+				test.That(t, report.numTests).Equals(25, "number of tests")
+				test.That(t, report.numPassed).Equals(14, "tests passed")
+				test.That(t, report.numFailed).Equals(10, "tests failed")
+				test.That(t, report.numSkipped).Equals(1, "tests skipped")
+
+				for _, ti := range report.packages[0].tests {
+					t.Run(ti.path, func(t *testing.T) {
+						name := ti.path[strings.LastIndex(ti.path, "/")+1:]
+						isCase := strings.HasPrefix(name, "case-")
+						n := 0
+						_, _ = fmt.Sscanf(name, "case-%d", &n)
+
+						// results are attributed to the correct test
+						want := map[bool]testResult{true: trFailed, false: trPassed}
+						switch {
+						case ti.path == "TestParallel" || ti.path == "TestParallelA":
+							test.That(t, ti.result).Equals(trFailed, "result")
+						case ti.path == "TestParallelC":
+							test.That(t, ti.result).Equals(trSkipped, "result")
+						case strings.HasPrefix(ti.path, "TestParallel/"):
+							test.That(t, ti.result).Equals(want[n%2 == 1], "result")
+						default:
+							test.That(t, ti.result).Equals(trPassed, "result")
+						}
+
+						// output is attributed to the correct test and
+						// does not include any test runner frames
+						for ref, lines := range ti.output {
+							for _, s := range lines {
+								test.IsFalse(t, strings.HasPrefix(s, "=== "), ref+": frame recorded as output")
+								test.IsFalse(t, strings.HasPrefix(s, "--- "), ref+": frame recorded as output")
+								test.IsTrue(t, strings.HasSuffix(s, " "+ti.path), ref+": output from another test: "+s)
+							}
+						}
+
+						// parallel state and wall-clock times are recorded
+						test.That(t, ti.parallel).Equals(isCase || ti.path != "TestParallel", "parallel")
+						test.IsFalse(t, ti.paused, "paused")
+						test.IsFalse(t, ti.started.IsZero(), "started")
+						test.IsFalse(t, ti.ended.Before(ti.started), "ended before started")
+					})
+				}
+			},
+		},
 		{scenario: "new-test/output.json",
 			exec: func(t *testing.T) {
 				report := &testrun{}
@@ -124,12 +310,581 @@ func TestParse(t *testing.T) {
 				// ASSERT
 				test.Error(t, err).IsNil()
 				test.That(t, len(report.packages)).Equals(11, "number of packages")
-				test.That(t, report.numTests).Equals(896, "number of tests")
-				test.That(t, report.numPassed).Equals(311, "tests passed")
-				test.That(t, report.numFailed).Equals(372, "tests failed")
+				test.That(t, report.numTests).Equals(549, "number of tests")
+				test.That(t, report.numPassed).Equals(172, "tests passed")
+				test.That(t, report.numFailed).Equals(376, "tests failed")
 				test.That(t, report.numSkipped).Equals(1, "tests skipped")
 			},
 		},
+		{scenario: "panic.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/panic.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numTests).Equals(2, "number of tests")
+				test.That(t, report.numPassed).Equals(1, "tests passed")
+				test.That(t, report.numFailed).Equals(1, "tests failed")
+
+				ti := report.packages[0].tests[1]
+				test.That(t, ti.path).Equals("TestPanics")
+				test.That(t, ti.result).Equals(trFailed)
+				test.Map(t, ti.output).Equals(map[string][]string{
+					"panic_test.go:10": {"output before the panic"},
+				})
+				test.IsTrue(t, ti.panic != nil, "panic recorded")
+				test.Strings(t, []string{ti.panic.message}).Contains("assignment to entry in nil map")
+				test.IsFalse(t, ti.panic.timeout, "timeout")
+
+				frame := slices.IndexFunc(ti.panic.stack, func(f stackframe) bool {
+					return f.function == "github.com/blugnu/test-report/internal/testdata/panic.TestPanics"
+				})
+				test.IsTrue(t, frame > 0, "test function in stack")
+				test.IsTrue(t, strings.HasSuffix(ti.panic.stack[frame].file, "panic_test.go"), "test function file")
+				test.That(t, ti.panic.stack[frame].line).Equals(12)
+				test.IsTrue(t, report.packages[0].panic == nil, "package panic")
+			},
+		},
+		{scenario: "timeout.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/timeout.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numTests).Equals(2, "number of tests")
+				test.That(t, report.numPassed).Equals(1, "tests passed")
+				test.That(t, report.numFailed).Equals(1, "tests failed")
+
+				ti := report.packages[0].tests[1]
+				test.That(t, ti.path).Equals("TestTimesOut")
+				test.That(t, ti.result).Equals(trFailed)
+				test.IsTrue(t, ti.panic != nil, "panic recorded")
+				test.That(t, ti.panic.message).Equals("test timed out after 1s")
+				test.IsTrue(t, ti.panic.timeout, "timeout")
+				test.IsTrue(t, slices.ContainsFunc(ti.panic.stack, func(f stackframe) bool {
+					return f.function == "github.com/blugnu/test-report/internal/testdata/timeout.TestTimesOut"
+				}), "stack of goroutine running the test")
+			},
+		},
+		{scenario: "panic in package output",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkg"}
+{"Action":"run","Package":"pkg","Test":"TestPasses"}
+{"Action":"pass","Package":"pkg","Test":"TestPasses","Elapsed":0}
+{"Action":"run","Package":"pkg","Test":"TestSlow"}
+{"Action":"run","Package":"pkg","Test":"TestPanics"}
+{"Action":"output","Package":"pkg","Output":"panic: test timed out after 1s\n"}
+{"Action":"output","Package":"pkg","Output":"\trunning tests:\n"}
+{"Action":"output","Package":"pkg","Output":"\t\tTestSlow (1s)\n"}
+{"Action":"output","Package":"pkg","Output":"\n"}
+{"Action":"output","Package":"pkg","Output":"goroutine 8 [running]:\n"}
+{"Action":"output","Package":"pkg","Output":"testing.(*M).startAlarm.func1()\n"}
+{"Action":"output","Package":"pkg","Output":"\t/usr/local/go/src/testing/testing.go:2959 +0x34a\n"}
+{"Action":"output","Package":"pkg","Output":"\n"}
+{"Action":"output","Package":"pkg","Output":"goroutine 7 [sleep]:\n"}
+{"Action":"output","Package":"pkg","Output":"pkg.TestSlow(0xc000007?)\n"}
+{"Action":"output","Package":"pkg","Output":"\t/src/pkg/pkg_test.go:14 +0x48\n"}
+{"Action":"output","Package":"pkg","Output":"FAIL\tpkg\t1.007s\n"}
+{"Action":"fail","Package":"pkg","Elapsed":1.007}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numFailed).Equals(2, "tests failed (timed out or not completed)")
+
+				tests := report.packages[0].tests
+				test.IsTrue(t, tests[0].panic == nil, "passed test panic")
+				test.IsTrue(t, tests[2].panic == nil, "TestPanics panic")
+				pi := tests[1].panic
+				test.IsTrue(t, pi != nil, "TestSlow panic")
+				test.Strings(t, pi.running).Equals([]string{"TestSlow"})
+				test.That(t, pi.stack).Equals([]stackframe{
+					{function: "pkg.TestSlow", file: "/src/pkg/pkg_test.go", line: 14},
+				})
+				test.That(t, len(pi.output)).Equals(11, "lines of output")
+			},
+		},
+		{scenario: "panic in package output/paused test",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkg"}
+{"Action":"run","Package":"pkg","Test":"TestParallel"}
+{"Action":"pause","Package":"pkg","Test":"TestParallel"}
+{"Action":"run","Package":"pkg","Test":"TestPanics"}
+{"Action":"output","Package":"pkg","Output":"panic: assignment to entry in nil map\n"}
+{"Action":"output","Package":"pkg","Output":"\n"}
+{"Action":"output","Package":"pkg","Output":"goroutine 7 [running]:\n"}
+{"Action":"output","Package":"pkg","Output":"pkg.TestPanics(0xc000007?)\n"}
+{"Action":"output","Package":"pkg","Output":"\t/src/pkg/pkg_test.go:12 +0x48\n"}
+{"Action":"output","Package":"pkg","Output":"FAIL\tpkg\t0.004s\n"}
+{"Action":"fail","Package":"pkg","Elapsed":0.004}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+
+				tests := report.packages[0].tests
+				test.IsTrue(t, tests[0].paused, "TestParallel paused")
+				test.IsTrue(t, tests[0].panic == nil, "TestParallel panic")
+				test.IsTrue(t, tests[1].panic != nil, "TestPanics panic")
+			},
+		},
+		{scenario: "panic in package output/re-run",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkg"}
+{"Action":"run","Package":"pkg","Test":"TestPanics"}
+{"Action":"output","Package":"pkg","Output":"panic: assignment to entry in nil map\n"}
+{"Action":"output","Package":"pkg","Output":"\n"}
+{"Action":"output","Package":"pkg","Output":"goroutine 7 [running]:\n"}
+{"Action":"output","Package":"pkg","Output":"pkg.TestPanics(0xc000007?)\n"}
+{"Action":"output","Package":"pkg","Output":"\t/src/pkg/pkg_test.go:12 +0x48\n"}
+{"Action":"output","Package":"pkg","Output":"FAIL\tpkg\t0.004s\n"}
+{"Action":"fail","Package":"pkg","Elapsed":0.004}
+{"Action":"start","Package":"pkg"}
+{"Action":"run","Package":"pkg","Test":"TestPanics"}
+{"Action":"output","Package":"pkg","Test":"TestPanics","Output":"=== RUN   TestPanics\n"}
+{"Time":"2026-10-18T06:41:05Z","Action":"pass","Package":"pkg","Test":"TestPanics","Elapsed":0}
+{"Action":"output","Package":"pkg","Output":"output after the re-run\n"}
+{"Action":"output","Package":"pkg","Output":"ok  \tpkg\t0.003s\n"}
+{"Action":"pass","Package":"pkg","Elapsed":0.003}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numFailed).Equals(0, "tests failed")
+				test.That(t, report.numPackageFailed).Equals(0, "packages failed")
+
+				pkg := report.packages[0]
+				test.IsTrue(t, pkg.panic == nil, "package panic")
+				ti := pkg.tests[0]
+				test.That(t, ti.attempts).Equals([]testResult{trFailed, trPassed})
+				test.IsTrue(t, ti.panic != nil, "panic in first attempt")
+				test.That(t, len(ti.panic.output)).Equals(5, "lines of panic output")
+				test.Strings(t, pkg.output).Contains("output after the re-run")
+			},
+		},
+		{scenario: "panic in package output/not attributed",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkg"}
+{"Action":"output","Package":"pkg","Output":"panic: in init\n"}
+{"Action":"output","Package":"pkg","Output":"\n"}
+{"Action":"output","Package":"pkg","Output":"goroutine 1 [running]:\n"}
+{"Action":"output","Package":"pkg","Output":"pkg.init.0()\n"}
+{"Action":"output","Package":"pkg","Output":"\t/src/pkg/pkg.go:4 +0x25\n"}
+{"Action":"output","Package":"pkg","Output":"FAIL\tpkg\t0.004s\n"}
+{"Action":"fail","Package":"pkg","Elapsed":0.004}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				pi := report.packages[0].panic
+				test.IsTrue(t, pi != nil, "package panic")
+				test.That(t, pi.message).Equals("in init")
+				test.That(t, pi.stack).Equals([]stackframe{
+					{function: "pkg.init.0", file: "/src/pkg/pkg.go", line: 4},
+				})
+			},
+		},
+		{scenario: "testmain.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/testmain.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numTests).Equals(1, "number of tests")
+				test.That(t, report.numPassed).Equals(1, "tests passed")
+				test.That(t, report.numFailed).Equals(0, "tests failed")
+				test.That(t, report.numPackageFailed).Equals(1, "packages failed")
+
+				pkg := report.packages[0]
+				test.IsFalse(t, pkg.passed, "package passed")
+				test.IsTrue(t, pkg.failed, "package failure")
+				test.Strings(t, pkg.output).Equals([]string{"TestMain: teardown failed"})
+			},
+		},
+		{scenario: "package output",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkga"}
+{"Action":"run","Package":"pkga","Test":"TestFails"}
+{"Action":"output","Package":"pkga","Test":"TestFails","Output":"--- FAIL: TestFails (0.00s)\n"}
+{"Action":"fail","Package":"pkga","Test":"TestFails","Elapsed":0}
+{"Action":"output","Package":"pkga","Output":"FAIL\n"}
+{"Action":"output","Package":"pkga","Output":"output from TestMain\n"}
+{"Action":"output","Package":"pkga","Output":"exit status 1\n"}
+{"Action":"output","Package":"pkga","Output":"FAIL\tpkga\t0.004s\n"}
+{"Action":"fail","Package":"pkga","Elapsed":0.004}
+{"Action":"start","Package":"pkgb"}
+{"Action":"output","Package":"pkgb","Output":"?   \tpkgb\t[no test files]\n"}
+{"Action":"skip","Package":"pkgb","Elapsed":0}
+{"Action":"start","Package":"pkgc"}
+{"Action":"output","Package":"pkgc","Output":"testing: warning: no tests to run\n"}
+{"Action":"output","Package":"pkgc","Output":"PASS\n"}
+{"Action":"output","Package":"pkgc","Output":"ok  \tpkgc\t0.004s [no tests to run]\n"}
+{"Action":"pass","Package":"pkgc","Elapsed":0.004}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numPackageFailed).Equals(0, "packages failed")
+
+				pkga, pkgb, pkgc := report.packages[0], report.packages[1], report.packages[2]
+				test.IsFalse(t, pkga.failed, "package with failed test is not a package failure")
+				test.Strings(t, pkga.output).Equals([]string{"output from TestMain"})
+				test.IsFalse(t, pkgb.failed, "skipped package is not a package failure")
+				test.That(t, len(pkgb.output)).Equals(0, "skipped package output")
+				test.Strings(t, pkgc.output).Equals([]string{"testing: warning: no tests to run"})
+			},
+		},
+		{scenario: "flaky.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/flaky.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numTests).Equals(2, "number of tests")
+				test.That(t, report.numPassed).Equals(2, "tests passed")
+				test.That(t, report.numFailed).Equals(0, "tests failed")
+				test.That(t, report.numFlaky).Equals(1, "flaky tests")
+				test.That(t, report.numPackageFailed).Equals(1, "packages failed")
+				test.IsTrue(t, report.packages[0].failed, "package failed")
+
+				tests := report.packages[0].tests
+				test.That(t, tests[0].path).Equals("TestFlaky")
+				test.That(t, tests[0].attempts).Equals([]testResult{trFailed, trPassed})
+				test.Strings(t, tests[0].output["flaky_test.go:10"]).Equals([]string{"failed on the first attempt"})
+				test.That(t, tests[1].attempts).Equals([]testResult{trPassed, trPassed})
+				test.IsFalse(t, tests[1].flaky(), "TestStable is flaky")
+			},
+		},
+		{scenario: "flaky.json/allow flaky",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/flaky.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{allowFlaky: true}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numFailed).Equals(0, "tests failed")
+				test.That(t, report.numFlaky).Equals(1, "flaky tests")
+				test.That(t, report.numPackageFailed).Equals(0, "packages failed")
+				test.IsFalse(t, report.packages[0].failed, "package failed")
+			},
+		},
+		{scenario: "packages.json/repeated",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				b, err := os.ReadFile("./testdata/packages.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				input := io.MultiReader(bytes.NewReader(b), bytes.NewReader(b))
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(report.packages)).Equals(2, "number of packages")
+				test.That(t, report.numTests).Equals(9, "number of tests")
+				test.That(t, report.numPassed).Equals(3, "tests passed")
+				test.That(t, report.numFailed).Equals(4, "tests failed")
+				test.That(t, report.numSkipped).Equals(2, "tests skipped")
+				test.That(t, report.numFlaky).Equals(0, "flaky tests")
+				test.That(t, len(report.packages[1].tests[1].attempts)).Equals(2, "attempts")
+			},
+		},
+		{scenario: "re-run of failed tests",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkga"}
+{"Action":"run","Package":"pkga","Test":"TestFlaky"}
+{"Action":"fail","Package":"pkga","Test":"TestFlaky","Elapsed":0.1}
+{"Action":"run","Package":"pkga","Test":"TestStable"}
+{"Action":"pass","Package":"pkga","Test":"TestStable","Elapsed":0.1}
+{"Action":"output","Package":"pkga","Output":"FAIL\n"}
+{"Action":"fail","Package":"pkga","Elapsed":0.2}
+{"Action":"start","Package":"pkga"}
+{"Action":"run","Package":"pkga","Test":"TestFlaky"}
+{"Action":"pass","Package":"pkga","Test":"TestFlaky","Elapsed":0.1}
+{"Action":"pass","Package":"pkga","Elapsed":0.1}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(report.packages)).Equals(1, "number of packages")
+				test.That(t, report.numTests).Equals(2, "number of tests")
+				test.That(t, report.numPassed).Equals(2, "tests passed")
+				test.That(t, report.numFlaky).Equals(1, "flaky tests")
+				test.IsTrue(t, report.packages[0].passed, "package passed")
+
+				tests := report.packages[0].tests
+				test.That(t, tests[0].attempts).Equals([]testResult{trFailed, trPassed})
+				test.That(t, len(tests[1].attempts)).Equals(0, "attempts of test not re-run")
+			},
+		},
+		{scenario: "race.json",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input, err := os.Open("./testdata/race.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				defer input.Close()
+				p := parser{}
+
+				// ACT
+				err = p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numTests).Equals(2, "number of tests")
+				test.That(t, report.numFailed).Equals(1, "tests failed")
+				test.That(t, report.numRaces).Equals(1, "data races")
+
+				ti := report.packages[0].tests[1]
+				test.That(t, ti.path).Equals("TestRace")
+				test.That(t, len(ti.races)).Equals(1, "races in test")
+				for _, lines := range ti.output {
+					for _, s := range lines {
+						test.IsFalse(t, strings.Contains(s, "DATA RACE"), "race report in output")
+					}
+				}
+
+				race := ti.races[0]
+				test.That(t, len(race.accesses)).Equals(2, "accesses")
+				test.IsTrue(t, strings.HasPrefix(race.accesses[0].description, "Write at ") || strings.HasPrefix(race.accesses[0].description, "Read at "), "first access")
+				test.IsTrue(t, strings.HasPrefix(race.accesses[1].description, "Previous "), "second access")
+				test.That(t, race.accesses[0].stack[0].function).Equals("github.com/blugnu/test-report/internal/testdata/race.TestRace.func1")
+				test.That(t, race.accesses[0].stack[0].line).Equals(13)
+				test.That(t, len(race.goroutines)).Equals(2, "goroutines")
+				test.IsTrue(t, strings.HasPrefix(race.goroutines[0].description, "Goroutine "), "goroutine creation site")
+			},
+		},
+		{scenario: "race in package output",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkg"}
+{"Action":"run","Package":"pkg","Test":"TestPasses"}
+{"Action":"pass","Package":"pkg","Test":"TestPasses","Elapsed":0}
+{"Action":"output","Package":"pkg","Output":"==================\n"}
+{"Action":"output","Package":"pkg","Output":"WARNING: DATA RACE\n"}
+{"Action":"output","Package":"pkg","Output":"Write at 0x00c0000182b8 by goroutine 8:\n"}
+{"Action":"output","Package":"pkg","Output":"  pkg.worker(0x1)\n"}
+{"Action":"output","Package":"pkg","Output":"      /src/pkg/pkg.go:8 +0x33\n"}
+{"Action":"output","Package":"pkg","Output":"\n"}
+{"Action":"output","Package":"pkg","Output":"Previous read at 0x00c0000182b8 by goroutine 7:\n"}
+{"Action":"output","Package":"pkg","Output":"  pkg.reader()\n"}
+{"Action":"output","Package":"pkg","Output":"      /src/pkg/pkg.go:12 +0x116\n"}
+{"Action":"output","Package":"pkg","Output":"\n"}
+{"Action":"output","Package":"pkg","Output":"Goroutine 8 (running) created at:\n"}
+{"Action":"output","Package":"pkg","Output":"  pkg.start()\n"}
+{"Action":"output","Package":"pkg","Output":"      /src/pkg/pkg.go:4 +0xf9\n"}
+{"Action":"output","Package":"pkg","Output":"==================\n"}
+{"Action":"output","Package":"pkg","Output":"testing: race detected outside of test execution\n"}
+{"Action":"output","Package":"pkg","Output":"FAIL\tpkg\t0.004s\n"}
+{"Action":"fail","Package":"pkg","Elapsed":0.004}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.numRaces).Equals(1, "data races")
+				test.That(t, report.numPackageFailed).Equals(1, "packages failed")
+
+				pkg := report.packages[0]
+				test.Strings(t, pkg.output).Equals([]string{"testing: race detected outside of test execution"})
+				test.That(t, pkg.races).Equals([]*raceinfo{{
+					accesses: []racestack{
+						{description: "Write at 0x00c0000182b8 by goroutine 8", stack: []stackframe{{function: "pkg.worker", file: "/src/pkg/pkg.go", line: 8}}},
+						{description: "Previous read at 0x00c0000182b8 by goroutine 7", stack: []stackframe{{function: "pkg.reader", file: "/src/pkg/pkg.go", line: 12}}},
+					},
+					goroutines: []racestack{
+						{description: "Goroutine 8 (running) created at", stack: []stackframe{{function: "pkg.start", file: "/src/pkg/pkg.go", line: 4}}},
+					},
+				}})
+			},
+		},
+		{scenario: "race in output/incomplete report",
+			exec: func(t *testing.T) {
+				p := parser{}
+
+				// ACT
+				output, races := p.extractRaces([]string{"before", "==================", "WARNING: DATA RACE"})
+
+				// ASSERT
+				test.Strings(t, output).Equals([]string{"before", "==================", "WARNING: DATA RACE"})
+				test.That(t, len(races)).Equals(0)
+			},
+		},
+		{scenario: "test elapsed",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkga"}
+{"Action":"run","Package":"pkga","Test":"TestPasses"}
+{"Action":"pass","Package":"pkga","Test":"TestPasses","Elapsed":1.25}
+{"Action":"run","Package":"pkga","Test":"TestFails"}
+{"Action":"fail","Package":"pkga","Test":"TestFails","Elapsed":0.5}
+{"Action":"run","Package":"pkga","Test":"TestSkips"}
+{"Action":"skip","Package":"pkga","Test":"TestSkips","Elapsed":0.001}
+{"Action":"fail","Package":"pkga","Elapsed":2}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				tests := report.packages[0].tests
+				test.That(t, tests[0].elapsed).Equals(1250 * time.Millisecond)
+				test.That(t, tests[1].elapsed).Equals(500 * time.Millisecond)
+				test.That(t, tests[2].elapsed).Equals(1 * time.Millisecond)
+				test.That(t, report.packages[0].elapsed).Equals(2 * time.Second)
+			},
+		},
+		{scenario: "run elapsed/timestamps",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Time":"2026-10-18T06:41:01Z","Action":"start","Package":"pkga"}
+{"Time":"2026-10-18T06:41:01.5Z","Action":"start","Package":"pkgb"}
+{"Time":"2026-10-18T06:41:03Z","Action":"pass","Package":"pkga","Elapsed":2}
+{"Time":"2026-10-18T06:41:04.25Z","Action":"pass","Package":"pkgb","Elapsed":0}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.elapsed).Equals(3250 * time.Millisecond)
+			},
+		},
+		{scenario: "run elapsed/no timestamps",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkga"}
+{"Action":"start","Package":"pkgb"}
+{"Action":"pass","Package":"pkga","Elapsed":2}
+{"Action":"pass","Package":"pkgb","Elapsed":0}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, report.elapsed).Equals(2 * time.Second)
+			},
+		},
+		{scenario: "coverage",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkga"}
+{"Action":"output","Package":"pkga","Output":"PASS\n"}
+{"Action":"output","Package":"pkga","Output":"coverage: 75.0% of statements\n"}
+{"Action":"output","Package":"pkga","Output":"ok  \tpkga\t0.004s\tcoverage: 75.0% of statements\n"}
+{"Action":"pass","Package":"pkga","Elapsed":0.004}
+{"Action":"start","Package":"pkgb"}
+{"Action":"output","Package":"pkgb","Output":"\tpkgb\t\tcoverage: 0.0% of statements\n"}
+{"Action":"skip","Package":"pkgb","Elapsed":0}
+{"Action":"start","Package":"pkgc"}
+{"Action":"output","Package":"pkgc","Output":"coverage: [no statements]\n"}
+{"Action":"pass","Package":"pkgc","Elapsed":0.004}
+`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				pct := func(f float64) *float64 { return &f }
+				test.That(t, report.packages[0].coverage).Equals(pct(75))
+				test.That(t, report.packages[1].coverage).Equals(pct(0))
+				test.That(t, report.packages[2].coverage).IsNil()
+				test.That(t, report.coverage).Equals(pct(37.5))
+				for _, pkg := range report.packages {
+					test.That(t, len(pkg.output)).Equals(0, "package output")
+				}
+			},
+		},
 		{scenario: "verbose==true",
 			exec: func(t *testing.T) {
 				report := &testrun{}
@@ -160,6 +915,70 @@ func TestParse(t *testing.T) {
 				stdout.IsEmpty()
 			},
 		},
+		{scenario: "invalid input",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				input := bytes.NewReader([]byte(`{"Action":"start","Package":"pkga"}
+{"Action":"run","Package":"pkga","Test":"TestPasses"}
+{"Action":"pass","Package":"pkga","Test":"Test`))
+				p := parser{}
+
+				// ACT
+				err := p.parse(input, report)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidInput)
+			},
+		},
+		{scenario: "parseFiles",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				p := parser{}
+
+				// ACT
+				err := p.parseFiles([]string{"./testdata/packages.json", "./testdata/race.json"}, report)
+
+				// ASSERT
+				test.Error(t, err).IsNil()
+				test.That(t, len(report.packages)).Equals(3, "number of packages")
+				test.That(t, report.numTests).Equals(11, "number of tests")
+				test.That(t, report.numRaces).Equals(1, "data races")
+			},
+		},
+		{scenario: "parseFiles/invalid file",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				b, err := os.ReadFile("./testdata/packages.json")
+				if err != nil {
+					t.Fatalf("error loading test data: %s", err)
+				}
+				truncated := filepath.Join(t.TempDir(), "truncated.json")
+				if err := os.WriteFile(truncated, b[:len(b)/2], 0o644); err != nil {
+					t.Fatalf("error writing test data: %s", err)
+				}
+				report := &testrun{}
+				p := parser{}
+
+				// ACT
+				err = p.parseFiles([]string{truncated, "./testdata/race.json"}, report)
+
+				// ASSERT
+				test.Error(t, err).Is(ErrInvalidInput)
+				test.Strings(t, []string{err.Error()}).Contains("truncated.json")
+			},
+		},
+		{scenario: "parseFiles/file not found",
+			exec: func(t *testing.T) {
+				report := &testrun{}
+				p := parser{}
+
+				// ACT
+				err := p.parseFiles([]string{"./testdata/missing.json"}, report)
+
+				// ASSERT
+				test.Error(t, err).Is(fs.ErrNotExist)
+			},
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.scenario, func(t *testing.T) {
@@ -7,14 +7,49 @@ type showUsage struct{}
 
 // Run prints the usage message.
 func (showUsage) Run(*Options) int {
-	fmt.Println("Usage: test-report [options]")
+	fmt.Println("Usage: test-report [options] [file ...]")
+	fmt.Println("       test-report merge [options] file ...")
 	fmt.Println("Options:")
 	fmt.Println("    -f, -full      complete test report (includes passed tests)")
 	fmt.Println("    -s, -summary   summary only")
 	fmt.Println("    -t, -title     report title (default: 'Test Report')")
 	fmt.Println()
 	fmt.Println("    -o, -output    output filename (default: 'test-report.md')")
+	fmt.Println("    -format        report format: 'markdown' (default), 'junit', 'html' or 'json'")
+	fmt.Println("    -github        append a markdown report to the GitHub Actions job summary and annotate failures")
 	fmt.Println()
+	fmt.Println("    -orange-threshold  pass rate %age for an orange report icon (default: 85)")
+	fmt.Println("    -yellow-threshold  pass rate %age for a yellow report icon (default: 95)")
+	fmt.Println()
+	fmt.Println("    -min-pass-rate           exit with an error if the pass rate is below this %age")
+	fmt.Println("    -max-failed              exit with an error if more tests fail (default: 0; -1: no maximum)")
+	fmt.Println("    -fail-on-skip            exit with an error if any tests are skipped")
+	fmt.Println("    -fail-on-flaky           exit with an error if any tests are flaky (pass only on a re-run)")
+	fmt.Println("    -allow-flaky             a package failing only due to flaky tests is not a failure")
+	fmt.Println("    -fail-on-no-tests        exit with an error if there are no tests")
+	fmt.Println("    -fail-on-build-failure   exit with an error if a package fails to build")
+	fmt.Println("    -fail-on-regression      exit with an error if tests or packages take longer than in the baseline")
+	fmt.Println()
+	fmt.Println("    -source-url    URL template for links to source references (default: GitHub, in Actions)")
+	fmt.Println("    -module-root   directory containing the go.mod of the module tested (default: .)")
+	fmt.Println("    -snippet-lines lines of source code to show around source references (default: 0)")
+	fmt.Println("    -coverprofile  coverage profile for statement-weighted coverage (go test -coverprofile)")
+	fmt.Println("    -baseline      go test -json log or json report to compare with (markdown only)")
+	fmt.Println("    -regression-threshold  minimum %age increase in elapsed time of a regression (default: 50)")
+	fmt.Println("    -regression-min        minimum increase in elapsed time of a regression (default: 1s)")
+	fmt.Println("    -history       history file to append the test run to, presenting trends (markdown only)")
+	fmt.Println("    -history-runs  number of runs presented in trends (default: 10)")
+	fmt.Println("    -slowest       number of slowest tests and packages to report (markdown only)")
+	fmt.Println("    -duration-budget  report tests taking longer than this duration, e.g. 2s (markdown only)")
+	fmt.Println()
+	fmt.Println("    -collapse      collapsible test output and packages (markdown only)")
+	fmt.Println("    -max-lines     maximum lines of each block of output (markdown only; default: 0, no limit)")
+	fmt.Println("    -max-size      maximum size of the report in bytes (markdown only; default: 1 MiB; -1: no limit)")
+	fmt.Println()
+	fmt.Println("    -c, -config    configuration file (default: '.test-report.json', if present)")
 	fmt.Println("    -h, -help      show this help message")
+	fmt.Println()
+	fmt.Println("Files may be specified as paths or glob patterns; if no files are")
+	fmt.Println("specified, input is read from stdin (which must be piped).")
 	return 0
 }
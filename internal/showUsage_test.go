@@ -17,14 +17,49 @@ func TestShowUsage(t *testing.T) {
 
 	// ASSERT
 	stdout.Equals([]string{
-		"Usage: test-report [options]",
+		"Usage: test-report [options] [file ...]",
+		"       test-report merge [options] file ...",
 		"Options:",
 		"    -f, -full      complete test report (includes passed tests)",
 		"    -s, -summary   summary only",
 		"    -t, -title     report title (default: 'Test Report')",
 		"",
 		"    -o, -output    output filename (default: 'test-report.md')",
+		"    -format        report format: 'markdown' (default), 'junit', 'html' or 'json'",
+		"    -github        append a markdown report to the GitHub Actions job summary and annotate failures",
 		"",
+		"    -orange-threshold  pass rate %age for an orange report icon (default: 85)",
+		"    -yellow-threshold  pass rate %age for a yellow report icon (default: 95)",
+		"",
+		"    -min-pass-rate           exit with an error if the pass rate is below this %age",
+		"    -max-failed              exit with an error if more tests fail (default: 0; -1: no maximum)",
+		"    -fail-on-skip            exit with an error if any tests are skipped",
+		"    -fail-on-flaky           exit with an error if any tests are flaky (pass only on a re-run)",
+		"    -allow-flaky             a package failing only due to flaky tests is not a failure",
+		"    -fail-on-no-tests        exit with an error if there are no tests",
+		"    -fail-on-build-failure   exit with an error if a package fails to build",
+		"    -fail-on-regression      exit with an error if tests or packages take longer than in the baseline",
+		"",
+		"    -source-url    URL template for links to source references (default: GitHub, in Actions)",
+		"    -module-root   directory containing the go.mod of the module tested (default: .)",
+		"    -snippet-lines lines of source code to show around source references (default: 0)",
+		"    -coverprofile  coverage profile for statement-weighted coverage (go test -coverprofile)",
+		"    -baseline      go test -json log or json report to compare with (markdown only)",
+		"    -regression-threshold  minimum %age increase in elapsed time of a regression (default: 50)",
+		"    -regression-min        minimum increase in elapsed time of a regression (default: 1s)",
+		"    -history       history file to append the test run to, presenting trends (markdown only)",
+		"    -history-runs  number of runs presented in trends (default: 10)",
+		"    -slowest       number of slowest tests and packages to report (markdown only)",
+		"    -duration-budget  report tests taking longer than this duration, e.g. 2s (markdown only)",
+		"",
+		"    -collapse      collapsible test output and packages (markdown only)",
+		"    -max-lines     maximum lines of each block of output (markdown only; default: 0, no limit)",
+		"    -max-size      maximum size of the report in bytes (markdown only; default: 1 MiB; -1: no limit)",
+		"",
+		"    -c, -config    configuration file (default: '.test-report.json', if present)",
 		"    -h, -help      show this help message",
+		"",
+		"Files may be specified as paths or glob patterns; if no files are",
+		"specified, input is read from stdin (which must be piped).",
 	})
 }
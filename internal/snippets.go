@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// snippetLine is a line of source code in a snippet.
+type snippetLine struct {
+	number int    // the line number
+	text   string // the source code
+	ref    bool   // true if this is the line identified by the source reference
+}
+
+// snippets provides snippets of the source code around source references
+// in test output, read from the files of the module tested.
+type snippets struct {
+	context int                 // the number of lines of context before and after the referenced line
+	files   map[string][]string // the lines of each file read, keyed by path (nil if the file could not be read)
+	*module
+}
+
+// newSnippets returns a snippets for a module providing the specified
+// number of lines of context.  If the number of lines is not greater than
+// zero or there is no module, nil is returned (no snippets are provided).
+func newSnippets(context int, mod *module) *snippets {
+	if context <= 0 || mod == nil {
+		return nil
+	}
+	return &snippets{
+		context: context,
+		files:   map[string][]string{},
+		module:  mod,
+	}
+}
+
+// snippet returns the lines of source code around a source reference
+// ("<filename>:<line #>") in output from a test in the specified package.
+// Tabs are replaced by 4 spaces and any indent common to all lines in the
+// snippet is removed.  If the referenced file cannot be read or does not
+// contain the referenced line, nil is returned.
+func (s *snippets) snippet(pkg string, ref string) []snippetLine {
+	if s == nil {
+		return nil
+	}
+
+	file, line, ok := splitRef(ref)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(line)
+	if err != nil {
+		return nil
+	}
+	if file = s.file(pkg, file); file == "" {
+		return nil
+	}
+
+	src, ok := s.files[file]
+	if !ok {
+		if b, err := osReadFile(filepath.Join(s.dir, filepath.FromSlash(file))); err == nil {
+			src = strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n")
+		}
+		s.files[file] = src
+	}
+	if n < 1 || n > len(src) {
+		return nil
+	}
+
+	from := max(1, n-s.context)
+	to := min(len(src), n+s.context)
+	result := make([]snippetLine, 0, to-from+1)
+	indent := -1
+	for i := from; i <= to; i++ {
+		text := strings.ReplaceAll(src[i-1], "\t", "    ")
+		if trimmed := strings.TrimLeft(text, " "); trimmed != "" {
+			if w := len(text) - len(trimmed); indent == -1 || w < indent {
+				indent = w
+			}
+		}
+		result = append(result, snippetLine{
+			number: i,
+			text:   text,
+			ref:    i == n,
+		})
+	}
+
+	// remove any indent common to all (non-blank) lines
+	for i, l := range result {
+		if len(l.text) >= indent && indent > 0 {
+			result[i].text = l.text[indent:]
+		}
+	}
+	return result
+}
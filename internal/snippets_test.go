@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestSnippets(t *testing.T) {
+	// ARRANGE
+	src := "package foo\n\nfunc TestFoo(t *testing.T) {\n\tt.Error(\"failed\")\n}\n"
+	reads := 0
+	defer test.Using(&osReadFile, func(name string) ([]byte, error) {
+		reads++
+		if name == filepath.Join("/module", "foo", "foo_test.go") {
+			return []byte(src), nil
+		}
+		return nil, errors.New("not found")
+	})()
+	defer test.Using(&osStat, func(string) (fs.FileInfo, error) { return nil, nil })()
+	mod := &module{path: "github.com/foo/repo", dir: "/module"}
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "new/no context",
+			exec: func(t *testing.T) {
+				test.That(t, newSnippets(0, mod)).IsNil()
+			},
+		},
+		{scenario: "new/no module",
+			exec: func(t *testing.T) {
+				test.That(t, newSnippets(2, nil)).IsNil()
+			},
+		},
+		{scenario: "snippet/nil",
+			exec: func(t *testing.T) {
+				var s *snippets
+				test.That(t, s.snippet("github.com/foo/repo/foo", "foo_test.go:4")).IsNil()
+			},
+		},
+		{scenario: "snippet",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				reads = 0
+				s := newSnippets(2, mod)
+
+				// ACT
+				result := s.snippet("github.com/foo/repo/foo", "foo_test.go:4")
+				_ = s.snippet("github.com/foo/repo/foo", "foo_test.go:3")
+
+				// ASSERT
+				test.That(t, result).Equals([]snippetLine{
+					{number: 2, text: ""},
+					{number: 3, text: "func TestFoo(t *testing.T) {"},
+					{number: 4, text: "    t.Error(\"failed\")", ref: true},
+					{number: 5, text: "}"},
+					{number: 6, text: ""},
+				})
+				test.That(t, reads).Equals(1, "file reads")
+			},
+		},
+		{scenario: "snippet/first line",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				s := newSnippets(1, mod)
+
+				// ACT
+				result := s.snippet("github.com/foo/repo/foo", "foo_test.go:1")
+
+				// ASSERT
+				test.That(t, result).Equals([]snippetLine{
+					{number: 1, text: "package foo", ref: true},
+					{number: 2, text: ""},
+				})
+			},
+		},
+		{scenario: "snippet/indented",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				s := newSnippets(1, mod)
+
+				// ACT
+				result := s.snippet("github.com/foo/repo/foo", "foo_test.go:4")
+
+				// ASSERT
+				test.That(t, result).Equals([]snippetLine{
+					{number: 3, text: "func TestFoo(t *testing.T) {"},
+					{number: 4, text: "    t.Error(\"failed\")", ref: true},
+					{number: 5, text: "}"},
+				})
+			},
+		},
+		{scenario: "snippet/common indent",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&src, "func TestFoo(t *testing.T) {\n\tif true {\n\n\t\tt.Error(\"failed\")\n\t}\n}\n")()
+				s := newSnippets(1, mod)
+
+				// ACT
+				result := s.snippet("github.com/foo/repo/foo", "foo_test.go:4")
+
+				// ASSERT
+				test.That(t, result).Equals([]snippetLine{
+					{number: 3, text: ""},
+					{number: 4, text: "    t.Error(\"failed\")", ref: true},
+					{number: 5, text: "}"},
+				})
+			},
+		},
+		{scenario: "snippet/unresolved",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				s := newSnippets(2, mod)
+
+				// ACT & ASSERT
+				test.That(t, s.snippet("github.com/foo/repo/foo", "foo_test.go")).IsNil()
+				test.That(t, s.snippet("github.com/foo/repo/foo", "foo_test.go:x")).IsNil()
+				test.That(t, s.snippet("github.com/foo/repo/foo", "foo_test.go:99")).IsNil()
+				test.That(t, s.snippet("github.com/foo/repo/foo", "bar_test.go:1")).IsNil()
+				test.That(t, s.snippet("github.com/bar/repo", "foo_test.go:1")).IsNil()
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
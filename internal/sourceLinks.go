@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// osGetenv is a function variable to facilitate testing.
+var osGetenv = os.Getenv
+
+// sourceLinks resolves source references in test output ("<filename>:<line #>")
+// to URLs of the corresponding file and line in the hosting repository.
+//
+// The URL is obtained from a template in which {path} is replaced by the
+// path of the file relative to the root of the repository and {line} by the
+// line number.
+type sourceLinks struct {
+	url string // the URL template
+	*module
+}
+
+// githubSourceURL returns a URL template for files in a GitHub repository,
+// using the environment variables set in GitHub Actions workflows.  If any
+// of the required variables are not set an empty string is returned.
+func githubSourceURL() string {
+	server := osGetenv("GITHUB_SERVER_URL")
+	repo := osGetenv("GITHUB_REPOSITORY")
+	sha := osGetenv("GITHUB_SHA")
+	if server == "" || repo == "" || sha == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/blob/%s/{path}#L{line}", server, repo, sha)
+}
+
+// newSourceLinks returns a sourceLinks for a module using the specified URL
+// template or, if no template is specified, a template for a GitHub
+// repository (see githubSourceURL).
+//
+// If there is no template or no module, nil is returned (source references
+// are not linked).
+func newSourceLinks(url string, mod *module) *sourceLinks {
+	url = coalesce(url, githubSourceURL())
+	if url == "" || mod == nil {
+		return nil
+	}
+	return &sourceLinks{url: url, module: mod}
+}
+
+// link returns the URL for a source reference ("<filename>:<line #>") in
+// output from a test in the specified package.  An empty string is returned
+// if the reference cannot be resolved (e.g. the package is not in the module).
+func (sl *sourceLinks) link(pkg string, ref string) string {
+	if sl == nil {
+		return ""
+	}
+
+	file, line, ok := splitRef(ref)
+	if !ok {
+		return ""
+	}
+	if file = sl.file(pkg, file); file == "" {
+		return ""
+	}
+
+	return strings.NewReplacer(
+		"{path}", path.Join(sl.repoDir, file),
+		"{line}", line,
+	).Replace(sl.url)
+}
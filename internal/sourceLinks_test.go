@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestSourceLinks(t *testing.T) {
+	// ARRANGE
+	env := map[string]string{}
+	defer test.Using(&osGetenv, func(name string) string { return env[name] })()
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "github/environment not set",
+			exec: func(t *testing.T) {
+				// ACT
+				result := githubSourceURL()
+
+				// ASSERT
+				test.That(t, result).Equals("")
+			},
+		},
+		{scenario: "github/environment set",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				env = map[string]string{
+					"GITHUB_SERVER_URL": "https://github.com",
+					"GITHUB_REPOSITORY": "foo/repo",
+					"GITHUB_SHA":        "abc123",
+				}
+				defer func() { env = map[string]string{} }()
+
+				// ACT
+				result := githubSourceURL()
+
+				// ASSERT
+				test.That(t, result).Equals("https://github.com/foo/repo/blob/abc123/{path}#L{line}")
+			},
+		},
+		{scenario: "new/no url template",
+			exec: func(t *testing.T) {
+				// ACT
+				result := newSourceLinks("", &module{path: "github.com/foo/repo"})
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "new/no module",
+			exec: func(t *testing.T) {
+				// ACT
+				result := newSourceLinks("https://example.com/{path}#{line}", nil)
+
+				// ASSERT
+				test.That(t, result).IsNil()
+			},
+		},
+		{scenario: "link",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&osStat, func(name string) (fs.FileInfo, error) {
+					if filepath.Base(name) == "testing.go" {
+						return nil, fs.ErrNotExist
+					}
+					return nil, nil
+				})()
+				sl := &sourceLinks{
+					url: "https://github.com/foo/repo/blob/abc123/{path}#L{line}",
+					module: &module{
+						path:    "github.com/foo/repo/modules/foo",
+						repoDir: "modules/foo",
+					},
+				}
+				testcases := []struct {
+					pkg    string
+					ref    string
+					result string
+				}{
+					{pkg: "github.com/foo/repo/modules/foo", ref: "foo_test.go:12",
+						result: "https://github.com/foo/repo/blob/abc123/modules/foo/foo_test.go#L12"},
+					{pkg: "github.com/foo/repo/modules/foo/internal/bar", ref: "bar_test.go:8",
+						result: "https://github.com/foo/repo/blob/abc123/modules/foo/internal/bar/bar_test.go#L8"},
+					{pkg: "github.com/foo/repo/modules/foobar", ref: "foobar_test.go:8", result: ""},
+					{pkg: "github.com/foo/repo/modules/foo", ref: "", result: ""},
+					{pkg: "github.com/foo/repo/modules/foo", ref: "testing.go:1865", result: ""},
+				}
+				for _, tc := range testcases {
+					// ACT
+					result := sl.link(tc.pkg, tc.ref)
+
+					// ASSERT
+					test.That(t, result).Equals(tc.result)
+				}
+			},
+		},
+		{scenario: "link/nil",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				var sl *sourceLinks
+
+				// ACT
+				result := sl.link("github.com/foo/repo", "foo_test.go:12")
+
+				// ASSERT
+				test.That(t, result).Equals("")
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package benchmarks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasses(t *testing.T) {}
+
+func BenchmarkJoin(b *testing.B) {
+	s := []string{"a", "b", "c"}
+	for i := 0; i < b.N; i++ {
+		_ = strings.Join(s, ",")
+	}
+}
+
+func BenchmarkAlloc(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = make([]byte, 64)
+	}
+}
+
+func BenchmarkMetric(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+	}
+	b.ReportMetric(42, "widgets/op")
+}
+
+func BenchmarkSub(b *testing.B) {
+	b.Run("small", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+		}
+	})
+}
@@ -0,0 +1,8 @@
+package buildfailure
+
+import "testing"
+
+func TestDoesNotCompile(t *testing.T) {
+	var n int = "not an int"
+	t.Log(undefined)
+}
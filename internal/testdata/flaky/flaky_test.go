@@ -0,0 +1,14 @@
+package flaky
+
+import "testing"
+
+var attempts int
+
+func TestFlaky(t *testing.T) {
+	attempts++
+	if attempts == 1 {
+		t.Error("failed on the first attempt")
+	}
+}
+
+func TestStable(t *testing.T) {}
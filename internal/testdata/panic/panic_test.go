@@ -0,0 +1,13 @@
+package panic
+
+import "testing"
+
+func TestPasses(t *testing.T) {
+	t.Log("this test passes")
+}
+
+func TestPanics(t *testing.T) {
+	t.Log("output before the panic")
+	var m map[string]int
+	m["key"] = 1
+}
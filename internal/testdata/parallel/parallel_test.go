@@ -0,0 +1,58 @@
+package parallel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// every line of output from each test includes the name of the test so that
+// the attribution of output to tests can be verified; tests sleep for varying
+// durations so that their execution (and output) is interleaved
+
+func TestParallel(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		i := i
+		t.Run(fmt.Sprintf("case-%d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Logf("output from %s", t.Name())
+			time.Sleep(time.Duration(16-i) * time.Millisecond)
+			t.Logf("more output from %s", t.Name())
+			if i%2 == 1 {
+				t.Errorf("failure in %s", t.Name())
+			}
+		})
+	}
+}
+
+func TestParallelNested(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 4; i++ {
+		i := i
+		t.Run(fmt.Sprintf("case-%d", i), func(t *testing.T) {
+			t.Parallel()
+			time.Sleep(time.Duration(4-i) * time.Millisecond)
+			t.Logf("output from %s", t.Name())
+		})
+	}
+}
+
+func TestParallelA(t *testing.T) {
+	t.Parallel()
+	t.Logf("output from %s", t.Name())
+	time.Sleep(5 * time.Millisecond)
+	t.Errorf("failure in %s", t.Name())
+}
+
+func TestParallelB(t *testing.T) {
+	t.Parallel()
+	t.Logf("output from %s", t.Name())
+	time.Sleep(2 * time.Millisecond)
+	t.Logf("more output from %s", t.Name())
+}
+
+func TestParallelC(t *testing.T) {
+	t.Parallel()
+	time.Sleep(1 * time.Millisecond)
+	t.Skipf("skipped %s", t.Name())
+}
@@ -0,0 +1,18 @@
+package race
+
+import "testing"
+
+func TestNoRace(t *testing.T) {
+	t.Log("this test passes")
+}
+
+func TestRace(t *testing.T) {
+	n := 0
+	done := make(chan bool)
+	go func() {
+		n++
+		done <- true
+	}()
+	n++
+	<-done
+}
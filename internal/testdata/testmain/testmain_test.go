@@ -0,0 +1,20 @@
+package testmain
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	fmt.Println("TestMain: teardown failed")
+	if code == 0 {
+		code = 1
+	}
+	os.Exit(code)
+}
+
+func TestPasses(t *testing.T) {
+	t.Log("this test passes")
+}
@@ -0,0 +1,15 @@
+package timeout
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasses(t *testing.T) {
+	t.Log("this test passes")
+}
+
+func TestTimesOut(t *testing.T) {
+	t.Log("output before the timeout")
+	time.Sleep(10 * time.Second)
+}
@@ -1,6 +1,12 @@
 package internal
 
 import (
+	"cmp"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -19,12 +25,29 @@ const (
 	trSkipped                   // the test was skipped
 )
 
+// String returns the name of the test result.
+func (r testResult) String() string {
+	return map[testResult]string{
+		trFailed:  "failed",
+		trPassed:  "passed",
+		trSkipped: "skipped",
+	}[r]
+}
+
 // testinfo contains information about a single test.
 type testinfo struct {
 	path        string        // the path to (name of) the test
 	result      testResult    // the result of the test
 	elapsed     time.Duration // the time taken to run the test (if recorded)
 	packageName string        // the name of the package containing the test
+	started     time.Time     // the (wall-clock) time the test started (if recorded)
+	ended       time.Time     // the (wall-clock) time the test ended (if recorded)
+	paused      bool          // true if the test is paused, waiting to run in parallel
+	parallel    bool          // true if the test was paused to run in parallel
+	conflict    bool          // true if merged reports contained different results for the test
+	panic       *panicinfo    // the panic (or timeout) that occurred in the test (if any)
+	races       []*raceinfo   // the data races detected during the test (if any)
+	attempts    []testResult  // the result of each attempt, if the test was run more than once (e.g. go test -count)
 
 	// the output of the test; during parsing all output is added to
 	// a "raw" item in the map.  Once all output has been parsed, the "raw"
@@ -34,23 +57,210 @@ type testinfo struct {
 	output map[string][]string
 }
 
+// flaky returns true if the test was run more than once and both passed and
+// failed.  The result of a flaky test is the result of the final attempt.
+func (t *testinfo) flaky() bool {
+	return slices.Contains(t.attempts, trPassed) && slices.Contains(t.attempts, trFailed)
+}
+
+// failedAny returns true if the test failed or, if the test was run more
+// than once, failed in any attempt.
+func (t *testinfo) failedAny() bool {
+	return t.result == trFailed || slices.Contains(t.attempts, trFailed)
+}
+
 // packageinfo contains information about a single package, including a
 // slice of testinfo items for each test in the package.
 type packageinfo struct {
-	name    string        // the name of the package
-	passed  bool          // true if all tests in the package passed
-	elapsed time.Duration // the time taken to run all tests in the package (if recorded)
-	tests   []*testinfo   // the tests in the package
+	name        string        // the name of the package
+	passed      bool          // true if all tests in the package passed
+	elapsed     time.Duration // the time taken to run all tests in the package (if recorded)
+	tests       []*testinfo   // the tests in the package
+	buildFailed bool          // true if the package (or its tests) failed to build
+	buildOutput []string      // the output of a failed build (e.g. compiler errors)
+	failed      bool          // true if the package failed without any failed test (e.g. an error in TestMain)
+	output      []string      // output not associated with any test (e.g. from TestMain)
+	coverage    *float64      // the %age of statements covered (nil: not reported)
+	benchmarks  []*benchmark  // the benchmarks in the package
+	panic       *panicinfo    // a panic (or timeout) in the package that could not be attributed to a test
+	races       []*raceinfo   // data races detected in the package that could not be attributed to a test
+}
+
+// panicinfo contains information about a panic, including a test timeout
+// (reported by the test runner as a panic).
+type panicinfo struct {
+	message string       // the panic message (e.g. "runtime error: ..." or "test timed out after 1s")
+	timeout bool         // true if the panic is a test timeout
+	running []string     // the tests running when a timeout occurred
+	stack   []stackframe // the stack of the goroutine that panicked (or, for a timeout, running the test)
+	output  []string     // the complete output of the panic, including all goroutines
+}
+
+// raceinfo contains information about a data race reported by the race
+// detector.
+type raceinfo struct {
+	accesses   []racestack // the conflicting accesses (the most recent first)
+	goroutines []racestack // the sites at which the goroutines involved were created
+}
+
+// racestack is a stack in a data race report, identifying a memory access
+// or the creation of a goroutine.
+type racestack struct {
+	description string       // e.g. "Read at 0x00c0000182b8 by goroutine 8" or "Goroutine 8 (running) created at"
+	stack       []stackframe // the stack
+}
+
+// stackframe is a frame in the stack of a goroutine.
+type stackframe struct {
+	function string // the function (including the package path)
+	file     string // the source file (as an absolute path, if known)
+	line     int    // the line number in the source file
+}
+
+// in returns true if the function of the frame is in the specified package
+// or any package below it (e.g. a module path).
+func (f stackframe) in(pkg string) bool {
+	return strings.HasPrefix(f.function, pkg+".") || strings.HasPrefix(f.function, pkg+"/")
+}
+
+// packageName returns the path of the package of the function of the frame.
+func (f stackframe) packageName() string {
+	i := strings.LastIndex(f.function, "/") + 1
+	if j := strings.Index(f.function[i:], "."); j != -1 {
+		return f.function[:i+j]
+	}
+	return f.function
+}
+
+// ref returns the source reference ("<filename>:<line #>") of the frame.
+func (f stackframe) ref() string {
+	return fmt.Sprintf("%s:%d", path.Base(filepath.ToSlash(f.file)), f.line)
+}
+
+// focusFrame returns the index of the first frame in a stack that is in the
+// specified module (or package, if there is no module), i.e. the frame most
+// likely to identify the cause of a panic (or race) in the code tested.  If
+// there is no such frame, -1 is returned.
+func focusFrame(stack []stackframe, mod *module, pkg string) int {
+	if mod != nil {
+		pkg = mod.path
+	}
+	return slices.IndexFunc(stack, func(f stackframe) bool { return f.in(pkg) })
+}
+
+// benchmark contains the results of a single benchmark.
+type benchmark struct {
+	name        string             // the name of the benchmark
+	failed      bool               // true if the benchmark failed
+	iterations  int                // the number of iterations run
+	nsPerOp     float64            // the time taken per iteration, in ns
+	bytesPerOp  *float64           // the bytes allocated per iteration (if reported)
+	allocsPerOp *float64           // the allocations per iteration (if reported)
+	metrics     map[string]float64 // any other metrics reported (e.g. MB/s or custom metrics), keyed by unit
 }
 
 // testrun contains information about a test run, including a slice of
 // packageinfo items for each package in the test run.
 type testrun struct {
-	elapsed       time.Duration  // the time taken to run all tests (if recorded)
-	packages      []*packageinfo // the packages in the test run
-	numFailed     int            // the number of failed tests
-	numPassed     int            // the number of passed tests
-	numTests      int            // the total number of tests
-	numSkipped    int            // the number of skipped tests
-	percentPassed int            // the percentage of tests that passed
+	elapsed          time.Duration  // the time taken to run all tests (if recorded)
+	coverage         *float64       // the %age of statements covered (nil: not reported)
+	packages         []*packageinfo // the packages in the test run
+	numBenchmarks    int            // the number of benchmarks
+	numBuildFailed   int            // the number of packages that failed to build
+	numConflicts     int            // the number of tests with conflicting results in merged reports
+	numFailed        int            // the number of failed tests
+	numFlaky         int            // the number of flaky tests (that both passed and failed when run more than once)
+	numPackageFailed int            // the number of packages that failed without any failed test
+	numPassed        int            // the number of passed tests
+	numRaces         int            // the number of data races detected
+	numTests         int            // the total number of tests
+	numSkipped       int            // the number of skipped tests
+	percentPassed    int            // the percentage of tests that passed
+}
+
+// recount recalculates the number of tests, results (and flaky tests),
+// benchmarks and build failures (and the percentage of tests passed and
+// coverage) from the packages in the testrun.
+func (tr *testrun) recount() {
+	tr.numBenchmarks = 0
+	tr.numBuildFailed = 0
+	tr.numConflicts = 0
+	tr.numFailed = 0
+	tr.numFlaky = 0
+	tr.numPackageFailed = 0
+	tr.numPassed = 0
+	tr.numRaces = 0
+	tr.numSkipped = 0
+	tr.numTests = 0
+	tr.percentPassed = 0
+	tr.coverage = tr.meanCoverage()
+
+	for _, p := range tr.packages {
+		if p.buildFailed {
+			tr.numBuildFailed++
+		}
+		tr.numBenchmarks += len(p.benchmarks)
+		if p.failed {
+			tr.numPackageFailed++
+		}
+		tr.numRaces += len(p.races)
+		tr.numTests += len(p.tests)
+		for _, t := range p.tests {
+			tr.numRaces += len(t.races)
+			if t.conflict {
+				tr.numConflicts++
+			}
+			if t.flaky() {
+				tr.numFlaky++
+			}
+			switch t.result {
+			case trFailed:
+				tr.numFailed++
+			case trPassed:
+				tr.numPassed++
+			case trSkipped:
+				tr.numSkipped++
+			}
+		}
+	}
+
+	if tr.numTests > 0 {
+		tr.percentPassed = (tr.numPassed * 100) / tr.numTests
+	}
+}
+
+// slowestTests returns the n slowest tests in the testrun, slowest first,
+// followed by any further tests that exceeded a duration budget (0: no
+// budget).  Skipped tests are not included; a negative n is treated as 0.
+func (tr *testrun) slowestTests(n int, budget time.Duration) []*testinfo {
+	n = max(n, 0)
+	tests := []*testinfo{}
+	for _, p := range tr.packages {
+		for _, t := range p.tests {
+			if t.result != trSkipped {
+				tests = append(tests, t)
+			}
+		}
+	}
+	slices.SortStableFunc(tests, func(a, b *testinfo) int { return cmp.Compare(b.elapsed, a.elapsed) })
+
+	for n < len(tests) && budget > 0 && tests[n].elapsed > budget {
+		n++
+	}
+	return tests[:min(n, len(tests))]
+}
+
+// slowestPackages returns the n slowest packages in the testrun, slowest
+// first.  Packages that failed to build are not included; a negative n is
+// treated as 0.
+func (tr *testrun) slowestPackages(n int) []*packageinfo {
+	n = max(n, 0)
+	pkgs := []*packageinfo{}
+	for _, p := range tr.packages {
+		if !p.buildFailed {
+			pkgs = append(pkgs, p)
+		}
+	}
+	slices.SortStableFunc(pkgs, func(a, b *packageinfo) int { return cmp.Compare(b.elapsed, a.elapsed) })
+	return pkgs[:min(n, len(pkgs))]
 }
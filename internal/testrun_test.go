@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blugnu/test"
+)
+
+func TestTestrun(t *testing.T) {
+	// ARRANGE
+	tr := &testrun{packages: []*packageinfo{
+		{name: "pkga", elapsed: 2 * time.Second, tests: []*testinfo{
+			{path: "TestA1", result: trPassed, elapsed: 500 * time.Millisecond},
+			{path: "TestA2", result: trSkipped, elapsed: 5 * time.Second},
+		}},
+		{name: "pkgb", elapsed: 3 * time.Second, tests: []*testinfo{
+			{path: "TestB1", result: trFailed, elapsed: 1500 * time.Millisecond},
+			{path: "TestB2", result: trPassed, elapsed: 100 * time.Millisecond},
+		}},
+		{name: "pkgc", buildFailed: true},
+	}}
+	a1, b1, b2 := tr.packages[0].tests[0], tr.packages[1].tests[0], tr.packages[1].tests[1]
+
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "slowestTests",
+			exec: func(t *testing.T) {
+				test.That(t, tr.slowestTests(2, 0)).Equals([]*testinfo{b1, a1})
+			},
+		},
+		{scenario: "slowestTests/more than tests",
+			exec: func(t *testing.T) {
+				test.That(t, tr.slowestTests(10, 0)).Equals([]*testinfo{b1, a1, b2})
+			},
+		},
+		{scenario: "slowestTests/budget",
+			exec: func(t *testing.T) {
+				test.That(t, tr.slowestTests(1, 200*time.Millisecond)).Equals([]*testinfo{b1, a1})
+			},
+		},
+		{scenario: "slowestTests/n < 0",
+			exec: func(t *testing.T) {
+				test.That(t, tr.slowestTests(-1, 0)).Equals([]*testinfo{})
+				test.That(t, tr.slowestTests(-1, time.Second)).Equals([]*testinfo{b1})
+			},
+		},
+		{scenario: "slowestPackages",
+			exec: func(t *testing.T) {
+				test.That(t, tr.slowestPackages(1)).Equals([]*packageinfo{tr.packages[1]})
+			},
+		},
+		{scenario: "slowestPackages/n < 0",
+			exec: func(t *testing.T) {
+				test.That(t, tr.slowestPackages(-1)).Equals([]*packageinfo{})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// testnode is a node in a tree of the tests in a package, built from the
+// "/" separated paths of the tests (see testTree).  The children of a node
+// are the subtests of the test.
+type testnode struct {
+	name     string      // the name of the test (the last element of the path)
+	path     string      // the path of the test
+	test     *testinfo   // the test (nil if the test is not in the testrun, only its subtests)
+	children []*testnode // the subtests of the test, in the order they were run
+	passed   int         // the number of passed subtests (at any depth)
+	failed   int         // the number of failed subtests (at any depth)
+	skipped  int         // the number of skipped subtests (at any depth)
+}
+
+// testTree returns the top-level tests in a package as a tree of tests and
+// subtests.  If a subtest is present without its parent (e.g. the parent
+// test was not included in a merged report), a node is added for the
+// parent with no test.
+func testTree(tests []*testinfo) []*testnode {
+	roots := []*testnode{}
+	nodes := map[string]*testnode{}
+
+	var node func(path string) *testnode
+	node = func(path string) *testnode {
+		if n, ok := nodes[path]; ok {
+			return n
+		}
+		n := &testnode{name: path, path: path}
+		nodes[path] = n
+		if i := strings.LastIndex(path, "/"); i != -1 {
+			n.name = path[i+1:]
+			parent := node(path[:i])
+			parent.children = append(parent.children, n)
+		} else {
+			roots = append(roots, n)
+		}
+		return n
+	}
+	for _, t := range tests {
+		node(t.path).test = t
+	}
+
+	for _, n := range roots {
+		n.count()
+	}
+	return roots
+}
+
+// count counts the results of the subtests of a node (at any depth).
+func (n *testnode) count() {
+	for _, c := range n.children {
+		c.count()
+		n.passed += c.passed
+		n.failed += c.failed
+		n.skipped += c.skipped
+		if c.test != nil {
+			n.add(c.test.result)
+		}
+	}
+}
+
+// add adds a result to the counts of a node.
+func (n *testnode) add(r testResult) {
+	switch r {
+	case trPassed:
+		n.passed++
+	case trFailed:
+		n.failed++
+	case trSkipped:
+		n.skipped++
+	}
+}
+
+// result returns the result of a node rolled up from its subtests: a node
+// has failed if the test or any subtest failed.  A node without a test
+// has passed if any subtest passed and is otherwise skipped.
+func (n *testnode) result() testResult {
+	switch {
+	case n.failed > 0:
+		return trFailed
+	case n.test != nil:
+		return n.test.result
+	case n.passed > 0:
+		return trPassed
+	default:
+		return trSkipped
+	}
+}
+
+// summary returns a summary of the results of the subtests of a node,
+// e.g. "1 failed, 2 passed".  Results with no subtests are omitted.
+func (n *testnode) summary() string {
+	s := []string{}
+	for _, r := range []struct {
+		n      int
+		result string
+	}{
+		{n.failed, "failed"},
+		{n.passed, "passed"},
+		{n.skipped, "skipped"},
+	} {
+		if r.n > 0 {
+			s = append(s, fmt.Sprintf("%d %s", r.n, r.result))
+		}
+	}
+	return strings.Join(s, ", ")
+}
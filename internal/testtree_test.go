@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestTestTree(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "no tests",
+			exec: func(t *testing.T) {
+				// ACT
+				result := testTree(nil)
+
+				// ASSERT
+				test.That(t, len(result)).Equals(0)
+			},
+		},
+		{scenario: "tests without subtests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tests := []*testinfo{
+					{path: "Test1", result: trPassed},
+					{path: "Test2", result: trFailed},
+				}
+
+				// ACT
+				result := testTree(tests)
+
+				// ASSERT
+				test.That(t, result).Equals([]*testnode{
+					{name: "Test1", path: "Test1", test: tests[0]},
+					{name: "Test2", path: "Test2", test: tests[1]},
+				})
+			},
+		},
+		{scenario: "subtests",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tests := []*testinfo{
+					{path: "Test1", result: trFailed},
+					{path: "Test1/a", result: trFailed},
+					{path: "Test1/a/x", result: trFailed},
+					{path: "Test1/a/y", result: trSkipped},
+					{path: "Test1/b", result: trPassed},
+				}
+
+				// ACT
+				result := testTree(tests)
+
+				// ASSERT
+				test.That(t, result).Equals([]*testnode{
+					{name: "Test1", path: "Test1", test: tests[0], passed: 1, failed: 2, skipped: 1,
+						children: []*testnode{
+							{name: "a", path: "Test1/a", test: tests[1], failed: 1, skipped: 1,
+								children: []*testnode{
+									{name: "x", path: "Test1/a/x", test: tests[2]},
+									{name: "y", path: "Test1/a/y", test: tests[3]},
+								},
+							},
+							{name: "b", path: "Test1/b", test: tests[4]},
+						},
+					},
+				})
+			},
+		},
+		{scenario: "subtest without parent",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				tests := []*testinfo{
+					{path: "Test1/a", result: trPassed},
+				}
+
+				// ACT
+				result := testTree(tests)
+
+				// ASSERT
+				test.That(t, result).Equals([]*testnode{
+					{name: "Test1", path: "Test1", passed: 1,
+						children: []*testnode{
+							{name: "a", path: "Test1/a", test: tests[0]},
+						},
+					},
+				})
+			},
+		},
+		{scenario: "result",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					*testnode
+					result testResult
+				}{
+					{&testnode{failed: 1, test: &testinfo{result: trPassed}}, trFailed},
+					{&testnode{passed: 1, test: &testinfo{result: trSkipped}}, trSkipped},
+					{&testnode{test: &testinfo{result: trFailed}}, trFailed},
+					{&testnode{passed: 1, skipped: 1}, trPassed},
+					{&testnode{skipped: 1}, trSkipped},
+				}
+				for _, tc := range testcases {
+					test.That(t, tc.testnode.result()).Equals(tc.result)
+				}
+			},
+		},
+		{scenario: "summary",
+			exec: func(t *testing.T) {
+				testcases := []struct {
+					*testnode
+					result string
+				}{
+					{&testnode{}, ""},
+					{&testnode{passed: 2}, "2 passed"},
+					{&testnode{failed: 1, passed: 2, skipped: 3}, "1 failed, 2 passed, 3 skipped"},
+					{&testnode{failed: 1, skipped: 3}, "1 failed, 3 skipped"},
+				}
+				for _, tc := range testcases {
+					test.That(t, tc.testnode.summary()).Equals(tc.result)
+				}
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package internal
+
+import "fmt"
+
+// thresholds are the pass rate %ages at (or above) which the icon for a
+// report changes from red to orange and from orange to yellow.  A report
+// is only green if all tests passed.
+type thresholds struct {
+	orange int
+	yellow int
+}
+
+// defaultThresholds are the thresholds used if none are configured.
+var defaultThresholds = thresholds{orange: 85, yellow: 95}
+
+// validate returns an error if the thresholds are not percentages or the
+// orange threshold is higher than the yellow threshold.
+func (t thresholds) validate() error {
+	if t.orange < 0 || t.yellow > 100 || t.orange > t.yellow {
+		return fmt.Errorf("%w: orange %d%%, yellow %d%% (must be 0 <= orange <= yellow <= 100)", ErrInvalidThresholds, t.orange, t.yellow)
+	}
+	return nil
+}
+
+// icon returns the report icon for a pass rate %age.
+func (t thresholds) icon(percentPassed int) string {
+	switch {
+	case percentPassed == 100:
+		return icon.greenBook
+	case percentPassed >= t.yellow:
+		return icon.yellowBook
+	case percentPassed >= t.orange:
+		return icon.orangeBook
+	default:
+		return icon.redBook
+	}
+}
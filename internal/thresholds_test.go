@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/blugnu/test"
+)
+
+func TestThresholds(t *testing.T) {
+	// ARRANGE
+	testcases := []struct {
+		scenario string
+		exec     func(t *testing.T)
+	}{
+		{scenario: "validate/valid",
+			exec: func(t *testing.T) {
+				for _, th := range []thresholds{
+					defaultThresholds,
+					{orange: 0, yellow: 0},
+					{orange: 100, yellow: 100},
+					{orange: 50, yellow: 50},
+				} {
+					test.Error(t, th.validate()).IsNil()
+				}
+			},
+		},
+		{scenario: "validate/invalid",
+			exec: func(t *testing.T) {
+				for _, th := range []thresholds{
+					{orange: -1, yellow: 95},
+					{orange: 85, yellow: 101},
+					{orange: 95, yellow: 85},
+				} {
+					test.Error(t, th.validate()).Is(ErrInvalidThresholds)
+				}
+			},
+		},
+		{scenario: "icon",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				th := thresholds{orange: 50, yellow: 75}
+
+				// ACT & ASSERT
+				test.That(t, th.icon(49)).Equals(icon.redBook)
+				test.That(t, th.icon(50)).Equals(icon.orangeBook)
+				test.That(t, th.icon(74)).Equals(icon.orangeBook)
+				test.That(t, th.icon(75)).Equals(icon.yellowBook)
+				test.That(t, th.icon(99)).Equals(icon.yellowBook)
+				test.That(t, th.icon(100)).Equals(icon.greenBook)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			tc.exec(t)
+		})
+	}
+}
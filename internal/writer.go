@@ -14,20 +14,39 @@ import (
 //
 // If the underlying writer returns an error, the error is stored in the
 // IndentWriter and no further output is written by any IndentWriter methods.
+//
+// If a limit is specified, writing more than the limit (in bytes) is an
+// error (ErrSizeLimitExceeded); the output that would exceed the limit is
+// not written.
 type IndentWriter struct {
 	output   io.Writer // the underlying writer
 	indent   string    // the current indent string
 	indented bool      // indicates if the indent has been written on the current line
+	limit    int       // the maximum number of bytes to be written (0: no limit)
+	written  int       // the number of bytes written
 	error              // any error that occurred during writing
 }
 
+// writeString writes a string to the output writer, counting the bytes
+// written.  If the string would exceed the limit of the writer, nothing
+// is written and the writer is placed in an error state.
+func (w *IndentWriter) writeString(s string) {
+	if w.limit > 0 && w.written+len(s) > w.limit {
+		w.error = ErrSizeLimitExceeded
+		return
+	}
+	var n int
+	n, w.error = io.WriteString(w.output, s)
+	w.written += n
+}
+
 // writeIndent writes the current indent string to the output writer (if not
 // already written and not in an error state) and clears the indented flag.
 func (w *IndentWriter) writeIndent() {
 	if w.indented || w.error != nil {
 		return
 	}
-	_, w.error = io.WriteString(w.output, w.indent)
+	w.writeString(w.indent)
 	w.indented = w.error == nil
 }
 
@@ -62,7 +81,7 @@ func (w *IndentWriter) Write(s string, args ...any) {
 		s = fmt.Sprintf(s, args...)
 	}
 	w.writeIndent()
-	_, w.error = io.WriteString(w.output, s)
+	w.writeString(s)
 }
 
 // WriteLn writes the specified arguments with a newline appended.
@@ -30,6 +30,23 @@ func TestWriter(t *testing.T) {
 				test.That(t, buf.Bytes()).IsNil()
 			},
 		},
+		{scenario: "limit",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				buf := bytes.NewBuffer(nil)
+				w := &IndentWriter{output: buf, limit: 10}
+
+				// ACT
+				w.WriteIndented(func() { w.WriteLn("abcdef") })
+				w.WriteLn("too long")
+				w.WriteLn("a")
+
+				// ASSERT
+				test.That(t, buf.String()).Equals("  abcdef\n")
+				test.That(t, w.written).Equals(9)
+				test.Error(t, w.error).Is(ErrSizeLimitExceeded)
+			},
+		},
 		{scenario: "WriteXMLElement bare",
 			exec: func(t *testing.T) {
 				// ARRANGE
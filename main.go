@@ -9,11 +9,13 @@ import (
 
 var osExit = os.Exit
 
-// main parses options and runs the command determined by those options
+// main parses options and runs the command determined by those options,
+// exiting with an error code if the options are invalid
 func main() {
 	opts := &internal.Options{}
 	if cmd, err := opts.Parse(); err != nil {
 		fmt.Println("ERROR:", err)
+		osExit(internal.ExitError)
 	} else {
 		osExit(cmd.Run(opts))
 	}
@@ -43,6 +43,8 @@ func TestMainFunc(t *testing.T) {
 				defer test.Using(&internal.ParseFlags, func(*flag.FlagSet, []string) error {
 					return errors.New("test error")
 				})()
+				exitCode := 0
+				defer test.Using(&osExit, func(code int) { exitCode = code })()
 
 				// ARRANGE ASSERT
 				defer test.ExpectPanic(nil).Assert(t)
@@ -52,6 +54,22 @@ func TestMainFunc(t *testing.T) {
 
 				// ASSERT
 				stdout.Contains("test error")
+				test.That(t, exitCode).Equals(-2)
+			},
+		},
+		{scenario: "invalid option",
+			exec: func(t *testing.T) {
+				// ARRANGE
+				defer test.Using(&os.Args, []string{"test-report", "-format", "bogus"})()
+				exitCode := 0
+				defer test.Using(&osExit, func(code int) { exitCode = code })()
+
+				// ACT
+				stdout, _ := test.CaptureOutput(t, main)
+
+				// ASSERT
+				stdout.Contains("invalid report format")
+				test.That(t, exitCode).Equals(-2)
 			},
 		},
 	}